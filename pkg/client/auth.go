@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// Authenticator provides the value of the Authorization header, it is called before every request
+	Authenticator interface {
+		Token(ctx context.Context) (string, error)
+	}
+
+	// Refresher can be implemented by an Authenticator that is able to renew its token.
+	// When a request is rejected with 401, Refresh is called and the request is retried once.
+	Refresher interface {
+		Refresh(ctx context.Context) error
+	}
+
+	// AuthenticatorFunc adapts a function to the Authenticator interface
+	AuthenticatorFunc func(ctx context.Context) (string, error)
+
+	staticAuthenticator struct {
+		token string
+	}
+
+	fileAuthenticator struct {
+		path    string
+		mu      sync.Mutex
+		token   string
+		modTime time.Time
+	}
+
+	envAuthenticator struct {
+		name string
+	}
+)
+
+func (f AuthenticatorFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// NewStaticAuthenticator returns an Authenticator that always uses the same api key
+func NewStaticAuthenticator(token string) Authenticator {
+	return &staticAuthenticator{token: token}
+}
+
+func (a *staticAuthenticator) Token(_ context.Context) (string, error) {
+	return a.token, nil
+}
+
+// NewFileAuthenticator returns an Authenticator that reads the token from a file,
+// and reads it again whenever the file changes (for example a rotated, mounted secret)
+func NewFileAuthenticator(path string) Authenticator {
+	return &fileAuthenticator{path: path}
+}
+
+func (a *fileAuthenticator) Token(_ context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	info, err := os.Stat(a.path)
+	if err != nil {
+		return "", fmt.Errorf("failed reading token file: %w", err)
+	}
+
+	if a.token != "" && info.ModTime().Equal(a.modTime) {
+		return a.token, nil
+	}
+
+	return a.read(info.ModTime())
+}
+
+func (a *fileAuthenticator) Refresh(_ context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	info, err := os.Stat(a.path)
+	if err != nil {
+		return fmt.Errorf("failed reading token file: %w", err)
+	}
+
+	_, err = a.read(info.ModTime())
+	return err
+}
+
+func (a *fileAuthenticator) read(modTime time.Time) (string, error) {
+	content, err := os.ReadFile(a.path)
+	if err != nil {
+		return "", fmt.Errorf("failed reading token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("token file %q is empty", a.path)
+	}
+
+	a.token = token
+	a.modTime = modTime
+	return token, nil
+}
+
+// NewEnvAuthenticator returns an Authenticator that reads the token from an environment variable on every request
+func NewEnvAuthenticator(name string) Authenticator {
+	return &envAuthenticator{name: name}
+}
+
+func (a *envAuthenticator) Token(_ context.Context) (string, error) {
+	token := os.Getenv(a.name)
+	if token == "" {
+		return "", fmt.Errorf("environment variable %s is not set", a.name)
+	}
+
+	return token, nil
+}
+
+// refreshToken calls the authenticator's Refresh hook, if it has one, and reports whether the request should be sent again
+func (c *CfClient) refreshToken(ctx context.Context) bool {
+	refresher, ok := c.auth.(Refresher)
+	if !ok {
+		return false
+	}
+
+	return refresher.Refresh(ctx) == nil
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codefresh-io/go-sdk/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type refreshingAuthenticator struct {
+	tokens    []string
+	refreshes int
+}
+
+func (a *refreshingAuthenticator) Token(_ context.Context) (string, error) {
+	return a.tokens[a.refreshes], nil
+}
+
+func (a *refreshingAuthenticator) Refresh(_ context.Context) error {
+	a.refreshes++
+	return nil
+}
+
+func TestCfClient_Authenticator_Refresh(t *testing.T) {
+	tests := []struct {
+		name          string
+		auth          Authenticator
+		statusCodes   []int
+		wantTokens    []string
+		wantRefreshes int
+		wantErr       string
+	}{
+		{
+			name:          "should refresh the token and retry once on 401",
+			auth:          &refreshingAuthenticator{tokens: []string{"old-token", "new-token"}},
+			statusCodes:   []int{401, 200},
+			wantTokens:    []string{"old-token", "new-token"},
+			wantRefreshes: 1,
+		},
+		{
+			name:          "should not retry more than once",
+			auth:          &refreshingAuthenticator{tokens: []string{"old-token", "new-token", "newer-token"}},
+			statusCodes:   []int{401, 401},
+			wantTokens:    []string{"old-token", "new-token"},
+			wantRefreshes: 1,
+			wantErr:       "API error: Unauthorized: denied",
+		},
+		{
+			name:        "should not retry when the authenticator can not refresh",
+			auth:        NewStaticAuthenticator("static-token"),
+			statusCodes: []int{401},
+			wantTokens:  []string{"static-token"},
+			wantErr:     "API error: Unauthorized: denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRT := mocks.NewMockRoundTripper(t)
+			cfClient := NewCfClientWithOptions(&ClientOptions{
+				Host:          "https://some.host",
+				Authenticator: tt.auth,
+				Client:        &http.Client{Transport: mockRT},
+			})
+			var tokens []string
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				tokens = append(tokens, req.Header.Get("Authorization"))
+				statusCode := tt.statusCodes[len(tokens)-1]
+				if statusCode == 401 {
+					return newResponse(statusCode, "denied"), nil
+				}
+
+				return newResponse(statusCode, "ok"), nil
+			}).Times(len(tt.statusCodes))
+
+			_, err := cfClient.RestAPI(context.Background(), &RequestOptions{Path: "/api/user"})
+			assert.Equal(t, tt.wantTokens, tokens)
+			if r, ok := tt.auth.(*refreshingAuthenticator); ok {
+				assert.Equal(t, tt.wantRefreshes, r.refreshes)
+			}
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCfClient_AppProxyClient_InheritsAuthenticator(t *testing.T) {
+	auth := AuthenticatorFunc(func(_ context.Context) (string, error) {
+		return "func-token", nil
+	})
+	cfClient := NewCfClientWithOptions(&ClientOptions{
+		Host:          "https://some.host",
+		Authenticator: auth,
+	})
+
+	proxyClient := cfClient.AppProxyClient("https://app-proxy.host", false)
+	token, err := proxyClient.auth.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "func-token", token)
+}
+
+func Test_fileAuthenticator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	auth := NewFileAuthenticator(path)
+
+	_, err := auth.Token(context.Background())
+	assert.ErrorContains(t, err, "failed reading token file")
+
+	assert.NoError(t, os.WriteFile(path, []byte("first-token\n"), 0600))
+	token, err := auth.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "first-token", token)
+
+	// rotate the secret
+	assert.NoError(t, os.WriteFile(path, []byte("second-token"), 0600))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	token, err = auth.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "second-token", token)
+
+	// refresh reads the file even when the modification time did not change
+	info, _ := os.Stat(path)
+	assert.NoError(t, os.WriteFile(path, []byte("third-token"), 0600))
+	assert.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+	assert.NoError(t, auth.(Refresher).Refresh(context.Background()))
+	token, err = auth.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "third-token", token)
+}
+
+func Test_envAuthenticator(t *testing.T) {
+	auth := NewEnvAuthenticator("CF_TEST_TOKEN")
+	t.Setenv("CF_TEST_TOKEN", "")
+	_, err := auth.Token(context.Background())
+	assert.EqualError(t, err, "environment variable CF_TEST_TOKEN is not set")
+
+	t.Setenv("CF_TEST_TOKEN", "env-token")
+	token, err := auth.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "env-token", token)
+}
@@ -3,13 +3,14 @@ package client
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 type (
@@ -18,17 +19,38 @@ type (
 	}
 
 	ClientOptions struct {
-		Token       string
-		Host        string
-		Client      *http.Client
-		GraphqlPath string
+		Token string
+		// Authenticator overrides Token, when set
+		Authenticator Authenticator
+		Host          string
+		Client        *http.Client
+		GraphqlPath   string
+		Retry         *RetryPolicy
+		UserAgent     string
+		// Timeout overrides the timeout of Client, when set
+		Timeout time.Duration
+		// Insecure skips TLS certificate verification
+		Insecure bool
+		// Middlewares wrap every call, the first one is the outermost
+		Middlewares []Middleware
+		// Logger logs every request at debug level, when set
+		Logger *slog.Logger
+		// RateLimits enables client-side rate limiting, when set
+		RateLimits *RateLimits
 	}
 
 	CfClient struct {
-		token   string
-		baseUrl *url.URL
-		gqlUrl  *url.URL
-		client  *http.Client
+		token       string
+		auth        Authenticator
+		baseUrl     *url.URL
+		gqlUrl      *url.URL
+		client      *http.Client
+		retry       *RetryPolicy
+		userAgent   string
+		middlewares []Middleware
+		logger      *slog.Logger
+		limiter     *RateLimiter
+		appProxy    bool
 	}
 
 	RequestOptions struct {
@@ -36,17 +58,28 @@ type (
 		Method string
 		Query  map[string]any
 		Body   any
+		// Idempotent marks a POST or PATCH request as safe to retry
+		Idempotent bool
+		// NotIdempotent marks a GET or PUT request that has side effects, so it is only retried like a POST
+		NotIdempotent bool
 	}
 
-	ApiError struct {
-		status     string
-		statusCode int
-		body       string
+	GraphqlError struct {
+		Message    string                  `json:"message"`
+		Path       []any                   `json:"path,omitempty"`
+		Locations  []GraphqlErrorLocation  `json:"locations,omitempty"`
+		Extensions *GraphqlErrorExtensions `json:"extensions,omitempty"`
 	}
 
-	GraphqlError struct {
-		Message    string
-		Extensions any
+	GraphqlErrorLocation struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	}
+
+	GraphqlErrorExtensions struct {
+		Code string
+		// Fields holds all extension values, including the code
+		Fields map[string]any
 	}
 
 	GraphqlErrorResponse struct {
@@ -54,6 +87,11 @@ type (
 		concatenatedErrors string
 	}
 
+	// GraphqlPartialError is returned together with the data, when the server resolved only part of the query
+	GraphqlPartialError struct {
+		*GraphqlErrorResponse
+	}
+
 	GraphqlBaseResponse struct {
 		Errors []GraphqlError
 	}
@@ -61,47 +99,114 @@ type (
 	GraphqlVoidResponse struct{}
 )
 
-func (e *ApiError) Error() string {
-	return fmt.Sprintf("API error: %s: %s", e.status, e.body)
+// NewClient creates a client for the platform at host, which must be an absolute http(s) url
+func NewClient(host string, opts ...Option) (*CfClient, error) {
+	opt := &ClientOptions{Host: host}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	return newCfClient(opt)
 }
 
+// Deprecated: use NewClient, which returns an error instead of panicking
 func NewCfClient(host, token, graphqlPath string, httpClient *http.Client) *CfClient {
-	baseUrl, err := url.Parse(host)
+	return NewCfClientWithOptions(&ClientOptions{
+		Host:        host,
+		Token:       token,
+		GraphqlPath: graphqlPath,
+		Client:      httpClient,
+	})
+}
+
+// Deprecated: use NewClient, which returns an error instead of panicking
+func NewCfClientWithOptions(opt *ClientOptions) *CfClient {
+	c, err := newCfClient(opt)
 	if err != nil {
 		panic(err)
 	}
 
+	return c
+}
+
+func newCfClient(opt *ClientOptions) (*CfClient, error) {
+	baseUrl, err := parseHost(opt.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	graphqlPath := opt.GraphqlPath
 	if graphqlPath == "" {
 		graphqlPath = "/2.0/api/graphql"
 	}
 
 	gqlUrl := baseUrl.JoinPath(graphqlPath)
-	if httpClient == nil {
-		httpClient = &http.Client{}
+	httpClient, err := buildHttpClient(opt)
+	if err != nil {
+		return nil, err
 	}
 
-	return &CfClient{
-		baseUrl: baseUrl,
-		token:   token,
-		gqlUrl:  gqlUrl,
-		client:  httpClient,
+	auth := opt.Authenticator
+	if auth == nil {
+		auth = NewStaticAuthenticator(opt.Token)
+	}
+
+	var limiter *RateLimiter
+	if opt.RateLimits != nil {
+		limiter = NewRateLimiter(*opt.RateLimits)
 	}
+
+	return &CfClient{
+		baseUrl:     baseUrl,
+		token:       opt.Token,
+		auth:        auth,
+		gqlUrl:      gqlUrl,
+		client:      httpClient,
+		retry:       opt.Retry,
+		userAgent:   opt.UserAgent,
+		middlewares: append([]Middleware(nil), opt.Middlewares...),
+		logger:      opt.Logger,
+		limiter:     limiter,
+	}, nil
 }
 
+// Deprecated: use NewAppProxyClient, which returns an error instead of panicking
 func (c *CfClient) AppProxyClient(host string, insecure bool) *CfClient {
-	httpClient := &http.Client{}
-	httpClient.Timeout = c.client.Timeout
-	if insecure {
-		customTransport := http.DefaultTransport.(*http.Transport).Clone()
-		customTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-		httpClient.Transport = customTransport
+	proxyClient, err := c.NewAppProxyClient(host, insecure)
+	if err != nil {
+		panic(err)
+	}
+
+	return proxyClient
+}
+
+// NewAppProxyClient returns a client of the app-proxy at host, with the auth, retry, middlewares and rate limits of c
+func (c *CfClient) NewAppProxyClient(host string, insecure bool) (*CfClient, error) {
+	proxyClient, err := newCfClient(&ClientOptions{
+		Host:          host,
+		Token:         c.token,
+		Authenticator: c.auth,
+		GraphqlPath:   "/app-proxy/api/graphql",
+		Client:        &http.Client{},
+		Timeout:       c.client.Timeout,
+		Insecure:      insecure,
+		Retry:         c.retry,
+		UserAgent:     c.userAgent,
+		Middlewares:   c.middlewares,
+		Logger:        c.logger,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return NewCfClient(host, c.token, "/app-proxy/api/graphql", httpClient)
+	// all app-proxy clients share the limits of their parent client
+	proxyClient.limiter = c.limiter
+	proxyClient.appProxy = true
+	return proxyClient, nil
 }
 
 func (c *CfClient) RestAPI(ctx context.Context, opt *RequestOptions) ([]byte, error) {
-	res, err := c.apiCall(ctx, c.baseUrl, opt)
+	res, err := c.apiCall(ctx, c.baseUrl, opt, CallInfo{Kind: CallKindRest})
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +225,36 @@ func (c *CfClient) RestAPI(ctx context.Context, opt *RequestOptions) ([]byte, er
 	return bytes, nil
 }
 func (c *CfClient) NativeRestAPI(ctx context.Context, opt *RequestOptions) (*http.Response, error) {
-	return c.apiCall(ctx, c.baseUrl, opt)
+	return c.apiCall(ctx, c.baseUrl, opt, CallInfo{Kind: CallKindRest})
+}
+
+// Download gets an absolute url, such as a signed storage url, without sending the auth token.
+// The caller must close the returned body
+func (c *CfClient) Download(ctx context.Context, rawUrl string) (io.ReadCloser, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, rawUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if c.userAgent != "" {
+		request.Header.Set("User-Agent", c.userAgent)
+	}
+
+	// the download goes through the middlewares, rate limiter and retries like any other call, only without the token
+	maxAttempts := c.retry.maxAttempts(true)
+	res, err := c.chain(func(req *http.Request, info CallInfo) (*http.Response, error) {
+		return c.send(req, info, maxAttempts, false)
+	})(request, CallInfo{Kind: CallKindRest})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err = c.wrapResponse(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
 }
 
 func (c *CfClient) GraphqlAPI(ctx context.Context, query string, variables any, result any) error {
@@ -128,9 +262,16 @@ func (c *CfClient) GraphqlAPI(ctx context.Context, query string, variables any,
 		"query":     query,
 		"variables": variables,
 	}
+	operationType, operation := parseGraphqlOperation(query)
 	res, err := c.apiCall(ctx, c.gqlUrl, &RequestOptions{
-		Method: "POST",
-		Body:   body,
+		Method:     "POST",
+		Body:       body,
+		Idempotent: operationType == "query",
+	}, CallInfo{
+		Kind:          CallKindGraphql,
+		Operation:     operation,
+		OperationType: operationType,
+		Variables:     variables,
 	})
 	if err != nil {
 		return err
@@ -155,7 +296,7 @@ func (c *CfClient) GraphqlAPI(ctx context.Context, query string, variables any,
 	return nil
 }
 
-func (c *CfClient) apiCall(ctx context.Context, baseUrl *url.URL, opt *RequestOptions) (*http.Response, error) {
+func (c *CfClient) apiCall(ctx context.Context, baseUrl *url.URL, opt *RequestOptions, info CallInfo) (*http.Response, error) {
 	var body []byte
 	finalUrl := baseUrl.JoinPath(opt.Path)
 	q := finalUrl.Query()
@@ -174,21 +315,98 @@ func (c *CfClient) apiCall(ctx context.Context, baseUrl *url.URL, opt *RequestOp
 		method = opt.Method
 	}
 
-	request, err := http.NewRequestWithContext(ctx, method, finalUrl.String(), bytes.NewBuffer(body))
+	request, err := http.NewRequestWithContext(ctx, method, finalUrl.String(), bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	request.Header.Set("Authorization", c.token)
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("origin", c.baseUrl.Host)
+	if c.userAgent != "" {
+		request.Header.Set("User-Agent", c.userAgent)
+	}
+
+	idempotent := !opt.NotIdempotent && (opt.Idempotent || isIdempotentMethod(method))
+	maxAttempts := c.retry.maxAttempts(idempotent)
+	return c.chain(func(req *http.Request, info CallInfo) (*http.Response, error) {
+		return c.send(req, info, maxAttempts, true)
+	})(request, info)
+}
+
+// send sends the request with the current token (when withToken is set), retrying it according to the retry policy
+func (c *CfClient) send(req *http.Request, info CallInfo, maxAttempts int, withToken bool) (*http.Response, error) {
+	ctx := req.Context()
+	class := c.endpointClass(info)
+	// a request without a token has nothing to refresh
+	refreshed := !withToken
+	for attempt := 1; ; attempt++ {
+		request, err := cloneRequest(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		if withToken {
+			token, err := c.auth.Token(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get auth token: %w", err)
+			}
+
+			request.Header.Set("Authorization", token)
+		}
+
+		release, err := c.limiter.acquire(ctx, class)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+
+		start := time.Now()
+		res, err := c.client.Do(request)
+		c.logRequest(request, info, attempt, time.Since(start), res, err)
+		c.limiter.observe(class, res)
+		if err != nil {
+			release()
+		} else {
+			res.Body = &releaseOnClose{ReadCloser: res.Body, release: release}
+		}
+
+		if err == nil && res.StatusCode == http.StatusUnauthorized && !refreshed && c.refreshToken(ctx) {
+			// send the same attempt again with the refreshed token
+			refreshed = true
+			discardResponse(res)
+			attempt--
+			continue
+		}
+
+		if attempt >= maxAttempts || !c.retry.shouldRetry(ctx, res, err) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to send request: %w", err)
+			}
+
+			return res, nil
+		}
+
+		delay := c.retry.backoff(attempt, res)
+		discardResponse(res)
+		if err = sleepContext(ctx, delay); err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+	}
+}
 
-	res, err := c.client.Do(request)
+// cloneRequest copies the request with a fresh body, so it can be sent more than once
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody == nil {
+		return clone, nil
+	}
+
+	body, err := req.GetBody()
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 
-	return res, nil
+	clone.Body = body
+	return clone, nil
 }
 
 func (c *CfClient) wrapResponse(res *http.Response) (*http.Response, error) {
@@ -200,11 +418,7 @@ func (c *CfClient) wrapResponse(res *http.Response) (*http.Response, error) {
 			body = fmt.Sprintf("failed to read response Body: %s", err.Error())
 		}
 
-		return nil, &ApiError{
-			status:     res.Status,
-			statusCode: res.StatusCode,
-			body:       body,
-		}
+		return nil, newApiError(res, body)
 	}
 	return res, nil
 }
@@ -230,8 +444,8 @@ func (e GraphqlBaseResponse) HasErrors() bool {
 func GraphqlAPI[T any](ctx context.Context, client *CfClient, query string, variables any) (T, error) {
 	var (
 		wrapper struct {
-			Data   map[string]T   `json:"data,omitempty"`
-			Errors []GraphqlError `json:"errors,omitempty"`
+			Data   map[string]json.RawMessage `json:"data,omitempty"`
+			Errors []GraphqlError             `json:"errors,omitempty"`
 		}
 		result T
 	)
@@ -242,16 +456,30 @@ func GraphqlAPI[T any](ctx context.Context, client *CfClient, query string, vari
 	}
 
 	// we assume there is only a single data key in the result (= a single query in the request)
+	var data json.RawMessage
 	for k := range wrapper.Data {
-		result = wrapper.Data[k]
+		data = wrapper.Data[k]
 		break
 	}
 
-	if wrapper.Errors != nil {
-		err = &GraphqlErrorResponse{Errors: wrapper.Errors}
+	hasData := len(data) > 0 && string(data) != "null"
+	if hasData {
+		err = json.Unmarshal(data, &result)
+		if err != nil {
+			return result, fmt.Errorf("failed to unmarshal response Body: %w", err)
+		}
+	}
+
+	if len(wrapper.Errors) == 0 {
+		return result, nil
+	}
+
+	gqlErr := &GraphqlErrorResponse{Errors: wrapper.Errors}
+	if hasData {
+		return result, &GraphqlPartialError{gqlErr}
 	}
 
-	return result, err
+	return result, gqlErr
 }
 
 func setQueryParams(q url.Values, query map[string]any) error {
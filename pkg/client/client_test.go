@@ -129,6 +129,39 @@ func TestCfClient_AppProxyClient(t *testing.T) {
 	}
 }
 
+func TestCfClient_NewAppProxyClient(t *testing.T) {
+	originalClient := NewCfClient("https://api.codefresh.io", "test-token", "", &http.Client{})
+	tests := []struct {
+		name    string
+		host    string
+		wantErr string
+	}{
+		{
+			name: "should create app proxy client",
+			host: "https://app-proxy.codefresh.io",
+		},
+		{
+			name:    "should fail on a host without a scheme",
+			host:    "app-proxy.codefresh.io",
+			wantErr: "scheme must be http or https",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxyClient, err := originalClient.NewAppProxyClient(tt.host, false)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Nil(t, proxyClient)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.host, proxyClient.baseUrl.String())
+			assert.True(t, proxyClient.appProxy)
+		})
+	}
+}
+
 func TestCfClient_RestAPI(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -308,6 +341,51 @@ func TestCfClient_NativeRestAPI(t *testing.T) {
 	}
 }
 
+func TestCfClient_Download(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		want       string
+		wantErr    string
+	}{
+		{
+			name:       "should return the body without sending the token",
+			statusCode: 200,
+			want:       "some content",
+		},
+		{
+			name:       "should fail on error response",
+			statusCode: 403,
+			wantErr:    "API error: : some content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := newMockClient(t)
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, "https://storage.host/some/file?signature=abc", req.URL.String())
+				assert.Empty(t, req.Header.Get("Authorization"))
+				return &http.Response{
+					StatusCode: tt.statusCode,
+					Body:       io.NopCloser(strings.NewReader("some content")),
+				}, nil
+			})
+
+			body, err := cfClient.Download(context.Background(), "https://storage.host/some/file?signature=abc")
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			defer body.Close()
+			got, err := io.ReadAll(body)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
 func TestCfClient_GraphqlAPI(t *testing.T) {
 	tests := []struct {
 		name      string
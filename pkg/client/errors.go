@@ -0,0 +1,244 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type (
+	// ApiError is returned for every response with a status code >= 400.
+	// Use errors.Is with one of the sentinel errors to check for a specific class of failure,
+	// or errors.As to access the full error details.
+	ApiError struct {
+		status     string
+		statusCode int
+		body       string
+		code       string
+		message    string
+		requestID  string
+	}
+
+	// apiErrorBody is the error structure returned by the Codefresh API
+	apiErrorBody struct {
+		Code      json.RawMessage `json:"code"`
+		Message   string          `json:"message"`
+		RequestID string          `json:"requestId"`
+		Context   struct {
+			RequestID string `json:"requestId"`
+		} `json:"context"`
+	}
+)
+
+// well known graphql error codes, as reported in the error extensions
+const (
+	GraphqlCodeUnauthenticated     = "UNAUTHENTICATED"
+	GraphqlCodeForbidden           = "FORBIDDEN"
+	GraphqlCodeNotFound            = "NOT_FOUND"
+	GraphqlCodeBadUserInput        = "BAD_USER_INPUT"
+	GraphqlCodeValidationFailed    = "GRAPHQL_VALIDATION_FAILED"
+	GraphqlCodeParseFailed         = "GRAPHQL_PARSE_FAILED"
+	GraphqlCodeConflict            = "CONFLICT"
+	GraphqlCodeRateLimited         = "RATE_LIMITED"
+	GraphqlCodeInternalServerError = "INTERNAL_SERVER_ERROR"
+)
+
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+func newApiError(res *http.Response, body string) *ApiError {
+	e := &ApiError{
+		status:     res.Status,
+		statusCode: res.StatusCode,
+		body:       body,
+		requestID:  res.Header.Get("X-Request-Id"),
+	}
+
+	parsed := apiErrorBody{}
+	if json.Unmarshal([]byte(body), &parsed) != nil {
+		return e
+	}
+
+	e.code = rawCodeString(parsed.Code)
+	e.message = parsed.Message
+	if parsed.RequestID != "" {
+		e.requestID = parsed.RequestID
+	} else if parsed.Context.RequestID != "" {
+		e.requestID = parsed.Context.RequestID
+	}
+
+	return e
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("API error: %s: %s", e.status, e.body)
+}
+
+// Is allows matching an ApiError against the sentinel errors, according to its status code
+func (e *ApiError) Is(target error) bool {
+	return target != nil && statusCodeError(e.statusCode) == target
+}
+
+// Status returns the response status line, e.g. "404 Not Found"
+func (e *ApiError) Status() string {
+	return e.status
+}
+
+// StatusCode returns the response status code
+func (e *ApiError) StatusCode() int {
+	return e.statusCode
+}
+
+// Body returns the raw response body
+func (e *ApiError) Body() string {
+	return e.body
+}
+
+// Code returns the Codefresh error code, if the body contained one
+func (e *ApiError) Code() string {
+	return e.code
+}
+
+// Message returns the Codefresh error message, if the body contained one
+func (e *ApiError) Message() string {
+	return e.message
+}
+
+// RequestID returns the request ID reported by the server, if any
+func (e *ApiError) RequestID() string {
+	return e.requestID
+}
+
+func statusCodeError(statusCode int) error {
+	switch {
+	case statusCode == http.StatusBadRequest:
+		return ErrBadRequest
+	case statusCode == http.StatusNotFound:
+		return ErrNotFound
+	case statusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case statusCode == http.StatusForbidden:
+		return ErrForbidden
+	case statusCode == http.StatusConflict:
+		return ErrConflict
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusCode >= http.StatusInternalServerError:
+		return ErrServer
+	}
+
+	return nil
+}
+
+// rawCodeString accepts both string and numeric error codes
+func rawCodeString(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var str string
+	if json.Unmarshal(raw, &str) == nil {
+		return str
+	}
+
+	return string(raw)
+}
+
+func (e *GraphqlError) Error() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%s (path: %s)", e.Message, e.PathString())
+}
+
+// Is allows matching a GraphqlError against the sentinel errors, according to its extensions code
+func (e *GraphqlError) Is(target error) bool {
+	return target != nil && graphqlCodeError(e.Code()) == target
+}
+
+// Code returns the error code from the extensions, or an empty string
+func (e *GraphqlError) Code() string {
+	if e.Extensions == nil {
+		return ""
+	}
+
+	return e.Extensions.Code
+}
+
+// PathString returns the path of the field that failed, e.g. "runtime.metadata.name"
+func (e *GraphqlError) PathString() string {
+	parts := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		parts[i] = fmt.Sprint(p)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func (e *GraphqlErrorExtensions) UnmarshalJSON(b []byte) error {
+	err := json.Unmarshal(b, &e.Fields)
+	if err != nil {
+		return err
+	}
+
+	e.Code, _ = e.Fields["code"].(string)
+	return nil
+}
+
+func (e GraphqlErrorExtensions) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]any, len(e.Fields)+1)
+	for k, v := range e.Fields {
+		fields[k] = v
+	}
+
+	if e.Code != "" {
+		fields["code"] = e.Code
+	}
+
+	return json.Marshal(fields)
+}
+
+// Unwrap returns every field error, so errors.Is and errors.As can match any of them
+func (e GraphqlErrorResponse) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i := range e.Errors {
+		errs[i] = &e.Errors[i]
+	}
+
+	return errs
+}
+
+func (e *GraphqlPartialError) Unwrap() error {
+	return e.GraphqlErrorResponse
+}
+
+func graphqlCodeError(code string) error {
+	switch code {
+	case GraphqlCodeUnauthenticated:
+		return ErrUnauthorized
+	case GraphqlCodeForbidden:
+		return ErrForbidden
+	case GraphqlCodeNotFound:
+		return ErrNotFound
+	case GraphqlCodeBadUserInput, GraphqlCodeValidationFailed, GraphqlCodeParseFailed:
+		return ErrBadRequest
+	case GraphqlCodeConflict:
+		return ErrConflict
+	case GraphqlCodeRateLimited:
+		return ErrRateLimited
+	case GraphqlCodeInternalServerError:
+		return ErrServer
+	}
+
+	return nil
+}
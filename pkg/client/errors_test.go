@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestApiError_Is(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		want       error
+		notWant    []error
+	}{
+		{
+			name:       "should match ErrNotFound on 404",
+			statusCode: 404,
+			want:       ErrNotFound,
+			notWant:    []error{ErrUnauthorized, ErrServer},
+		},
+		{
+			name:       "should match ErrUnauthorized on 401",
+			statusCode: 401,
+			want:       ErrUnauthorized,
+			notWant:    []error{ErrForbidden},
+		},
+		{
+			name:       "should match ErrForbidden on 403",
+			statusCode: 403,
+			want:       ErrForbidden,
+		},
+		{
+			name:       "should match ErrConflict on 409",
+			statusCode: 409,
+			want:       ErrConflict,
+		},
+		{
+			name:       "should match ErrRateLimited on 429",
+			statusCode: 429,
+			want:       ErrRateLimited,
+		},
+		{
+			name:       "should match ErrServer on 5xx",
+			statusCode: 503,
+			want:       ErrServer,
+		},
+		{
+			name:       "should match ErrBadRequest on 400",
+			statusCode: 400,
+			want:       ErrBadRequest,
+			notWant:    []error{ErrNotFound, ErrUnauthorized, ErrForbidden, ErrConflict, ErrRateLimited, ErrServer},
+		},
+		{
+			name:       "should not match any sentinel on 422",
+			statusCode: 422,
+			notWant:    []error{ErrBadRequest, ErrNotFound, ErrUnauthorized, ErrForbidden, ErrConflict, ErrRateLimited, ErrServer},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("failed getting something: %w", &ApiError{statusCode: tt.statusCode})
+			if tt.want != nil {
+				assert.ErrorIs(t, err, tt.want)
+			}
+
+			for _, notWant := range tt.notWant {
+				assert.NotErrorIs(t, err, notWant)
+			}
+		})
+	}
+}
+
+func Test_newApiError(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		header        http.Header
+		wantCode      string
+		wantMessage   string
+		wantRequestID string
+	}{
+		{
+			name:          "should parse codefresh error body",
+			body:          `{"status":404,"code":"1001","name":"NOT_FOUND","message":"Pipeline not found","requestId":"req-1"}`,
+			wantCode:      "1001",
+			wantMessage:   "Pipeline not found",
+			wantRequestID: "req-1",
+		},
+		{
+			name:          "should parse numeric code and request id from context",
+			body:          `{"code":1002,"message":"bad","context":{"requestId":"req-2"}}`,
+			wantCode:      "1002",
+			wantMessage:   "bad",
+			wantRequestID: "req-2",
+		},
+		{
+			name:          "should fallback to request id header",
+			body:          `{"message":"bad"}`,
+			header:        http.Header{"X-Request-Id": []string{"req-3"}},
+			wantMessage:   "bad",
+			wantRequestID: "req-3",
+		},
+		{
+			name: "should keep raw body when it is not json",
+			body: "Internal Server Error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{StatusCode: 404, Status: "404 Not Found", Header: tt.header}
+			err := newApiError(res, tt.body)
+			assert.Equal(t, 404, err.StatusCode())
+			assert.Equal(t, "404 Not Found", err.Status())
+			assert.Equal(t, tt.body, err.Body())
+			assert.Equal(t, tt.wantCode, err.Code())
+			assert.Equal(t, tt.wantMessage, err.Message())
+			assert.Equal(t, tt.wantRequestID, err.RequestID())
+		})
+	}
+}
+
+func TestCfClient_RestAPI_TypedError(t *testing.T) {
+	cfClient, mockRT := newMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).Return(newResponse(401, `{"message":"invalid token"}`), nil)
+
+	_, err := cfClient.RestAPI(context.Background(), &RequestOptions{Path: "/api/user"})
+	err = fmt.Errorf("failed getting current user: %w", err)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	var apiErr *ApiError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 401, apiErr.StatusCode())
+	assert.Equal(t, "invalid token", apiErr.Message())
+}
+
+func TestGraphqlAPI_TypedErrors(t *testing.T) {
+	type runtime struct {
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name        string
+		response    string
+		want        runtime
+		wantIs      error
+		wantPath    string
+		wantCode    string
+		wantPartial bool
+	}{
+		{
+			name:     "should map UNAUTHENTICATED to ErrUnauthorized",
+			response: `{"data": null, "errors": [{"message": "not logged in", "extensions": {"code": "UNAUTHENTICATED"}}]}`,
+			wantIs:   ErrUnauthorized,
+			wantCode: GraphqlCodeUnauthenticated,
+		},
+		{
+			name:     "should map NOT_FOUND to ErrNotFound and keep the path",
+			response: `{"data": {"runtime": null}, "errors": [{"message": "runtime not found", "path": ["runtime"], "locations": [{"line": 2, "column": 3}], "extensions": {"code": "NOT_FOUND"}}]}`,
+			wantIs:   ErrNotFound,
+			wantPath: "runtime",
+			wantCode: GraphqlCodeNotFound,
+		},
+		{
+			name:     "should map BAD_USER_INPUT to ErrBadRequest",
+			response: `{"errors": [{"message": "invalid name", "extensions": {"code": "BAD_USER_INPUT", "field": "name"}}]}`,
+			wantIs:   ErrBadRequest,
+			wantCode: GraphqlCodeBadUserInput,
+		},
+		{
+			name:        "should return partial data with a partial error",
+			response:    `{"data": {"runtime": {"name": "rt"}}, "errors": [{"message": "forbidden", "path": ["runtime", "cluster", 0], "extensions": {"code": "FORBIDDEN"}}]}`,
+			want:        runtime{Name: "rt"},
+			wantIs:      ErrForbidden,
+			wantPath:    "runtime.cluster.0",
+			wantCode:    GraphqlCodeForbidden,
+			wantPartial: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := newMockClient(t)
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).Return(newResponse(200, tt.response), nil)
+
+			got, err := GraphqlAPI[runtime](context.Background(), cfClient, "query GetRuntime { runtime { name } }", nil)
+			err = fmt.Errorf("failed getting a runtime: %w", err)
+			assert.Equal(t, tt.want, got)
+			assert.ErrorIs(t, err, tt.wantIs)
+
+			var gqlErr *GraphqlError
+			assert.True(t, errors.As(err, &gqlErr))
+			assert.Equal(t, tt.wantPath, gqlErr.PathString())
+			assert.Equal(t, tt.wantCode, gqlErr.Code())
+
+			var gqlResponse *GraphqlErrorResponse
+			assert.True(t, errors.As(err, &gqlResponse))
+
+			var partial *GraphqlPartialError
+			assert.Equal(t, tt.wantPartial, errors.As(err, &partial))
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Redacted replaces secret values in logs
+const Redacted = "[REDACTED]"
+
+// redactedHeaders are never logged
+var redactedHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// headerLogValue logs http headers, with credentials redacted
+type headerLogValue http.Header
+
+// WithLogger logs every request (method, path, graphql operation, duration and status) at debug level
+func WithLogger(logger *slog.Logger) Option {
+	return func(opt *ClientOptions) {
+		opt.Logger = logger
+	}
+}
+
+// Redact returns Redacted for a non-empty secret, so logs show whether a secret is set without exposing it
+func Redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	return Redacted
+}
+
+func (h headerLogValue) LogValue() slog.Value {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	attrs := make([]slog.Attr, 0, len(names))
+	for _, name := range names {
+		value := http.Header(h).Get(name)
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			value = Redact(value)
+		}
+
+		attrs = append(attrs, slog.String(name, value))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+func (c *CfClient) logRequest(req *http.Request, info CallInfo, attempt int, duration time.Duration, res *http.Response, err error) {
+	ctx := req.Context()
+	if c.logger == nil || !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.String("kind", string(info.Kind)),
+	}
+	if info.Operation != "" {
+		attrs = append(attrs, slog.String("operation", info.Operation))
+	}
+
+	attrs = append(attrs,
+		slog.Int("attempt", attempt),
+		slog.Duration("duration", duration),
+		slog.Any("headers", headerLogValue(req.Header)),
+	)
+	if res != nil {
+		attrs = append(attrs, slog.Int("status", res.StatusCode))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "codefresh api request", attrs...)
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/codefresh-io/go-sdk/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCfClient_Logger(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     slog.Level
+		call      func(c *CfClient) error
+		wantEntry map[string]any
+	}{
+		{
+			name:  "should log rest requests",
+			level: slog.LevelDebug,
+			call: func(c *CfClient) error {
+				_, err := c.RestAPI(context.Background(), &RequestOptions{Path: "/api/user"})
+				return err
+			},
+			wantEntry: map[string]any{
+				"method": "GET",
+				"path":   "/api/user",
+				"kind":   "rest",
+				"status": float64(200),
+			},
+		},
+		{
+			name:  "should log graphql operation",
+			level: slog.LevelDebug,
+			call: func(c *CfClient) error {
+				return c.GraphqlAPI(context.Background(), "query Me { me { id } }", nil, &map[string]any{})
+			},
+			wantEntry: map[string]any{
+				"method":    "POST",
+				"path":      "/2.0/api/graphql",
+				"kind":      "graphql",
+				"operation": "Me",
+				"status":    float64(200),
+			},
+		},
+		{
+			name:  "should not log above debug level",
+			level: slog.LevelInfo,
+			call: func(c *CfClient) error {
+				_, err := c.RestAPI(context.Background(), &RequestOptions{Path: "/api/user"})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: tt.level}))
+			mockRT := mocks.NewMockRoundTripper(t)
+			c, err := NewClient("https://some.host",
+				WithToken("secret-token"),
+				WithHTTPClient(&http.Client{Transport: mockRT}),
+				WithLogger(logger),
+			)
+			assert.NoError(t, err)
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).Return(newResponse(200, "{}"), nil)
+
+			assert.NoError(t, tt.call(c))
+			assert.NotContains(t, buf.String(), "secret-token")
+			if tt.wantEntry == nil {
+				assert.Empty(t, buf.String())
+				return
+			}
+
+			entry := map[string]any{}
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			assert.Equal(t, "DEBUG", entry["level"])
+			for k, v := range tt.wantEntry {
+				assert.Equal(t, v, entry[k], k)
+			}
+
+			headers := entry["headers"].(map[string]any)
+			assert.Equal(t, Redacted, headers["Authorization"])
+			assert.Equal(t, "application/json", headers["Content-Type"])
+			assert.Contains(t, entry, "duration")
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+)
+
+type (
+	// CallKind is the kind of api a call is made to
+	CallKind string
+
+	// CallInfo describes the sdk call a request belongs to
+	CallInfo struct {
+		Kind CallKind
+		// Operation is the graphql operation name, or the name of its first root field for anonymous operations.
+		// It is empty for rest calls.
+		Operation string
+		// OperationType is "query", "mutation" or "subscription" for graphql calls
+		OperationType string
+		// Variables are the graphql variables of the call, as passed to GraphqlAPI
+		Variables any
+	}
+
+	// Handler sends a request and returns its final response.
+	// The handler at the end of the chain sets the Authorization header, and handles retries and token refresh,
+	// so a middleware is called once per sdk call, and sees the response of the last attempt.
+	// A middleware that replaces the request body must also replace GetBody, which is used to send it again.
+	Handler func(req *http.Request, info CallInfo) (*http.Response, error)
+
+	// Middleware wraps a Handler, to inspect or modify requests and responses
+	Middleware func(next Handler) Handler
+)
+
+const (
+	CallKindRest    CallKind = "rest"
+	CallKindGraphql CallKind = "graphql"
+)
+
+// WithMiddleware adds middlewares to the client. The first middleware is the outermost one.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(opt *ClientOptions) {
+		opt.Middlewares = append(opt.Middlewares, middlewares...)
+	}
+}
+
+// Use appends middlewares to the chain of the client, after the ones already registered.
+// It is not safe to call Use concurrently with requests.
+func (c *CfClient) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// chain wraps handler with the registered middlewares
+func (c *CfClient) chain(handler Handler) Handler {
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		handler = c.middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// parseGraphqlOperation returns the type and name of the first operation in a graphql document.
+// Anonymous operations are named after their first root field.
+func parseGraphqlOperation(query string) (operationType, name string) {
+	s := skipIgnored(query)
+	if strings.HasPrefix(s, "{") {
+		return "query", firstField(s)
+	}
+
+	operationType, s = readName(s)
+	switch operationType {
+	case "query", "mutation", "subscription":
+	default:
+		return "", ""
+	}
+
+	name, s = readName(skipIgnored(s))
+	if name == "" {
+		name = firstField(s)
+	}
+
+	return operationType, name
+}
+
+// firstField returns the name of the first field in the selection set that follows the variable definitions
+func firstField(s string) string {
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '{':
+			if depth == 0 {
+				field, rest := readName(skipIgnored(s[i+1:]))
+				// an alias is followed by the field name
+				if rest = skipIgnored(rest); strings.HasPrefix(rest, ":") {
+					field, _ = readName(skipIgnored(rest[1:]))
+				}
+
+				return field
+			}
+		}
+	}
+
+	return ""
+}
+
+// skipIgnored skips whitespace, commas and comments
+func skipIgnored(s string) string {
+	for {
+		s = strings.TrimLeft(s, " \t\r\n,")
+		if !strings.HasPrefix(s, "#") {
+			return s
+		}
+
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			return ""
+		}
+
+		s = s[i:]
+	}
+}
+
+func readName(s string) (name, rest string) {
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			i++
+			continue
+		}
+
+		break
+	}
+
+	return s[:i], s[i:]
+}
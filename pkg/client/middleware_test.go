@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCfClient_Middleware(t *testing.T) {
+	var (
+		calls []string
+		infos []CallInfo
+	)
+	recorder := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(req *http.Request, info CallInfo) (*http.Response, error) {
+				calls = append(calls, name+":before")
+				infos = append(infos, info)
+				req.Header.Set("X-Request-Id", "some-request-id")
+				res, err := next(req, info)
+				calls = append(calls, name+":after")
+				return res, err
+			}
+		}
+	}
+
+	cfClient, mockRT := newRetryClient(t, testRetryPolicy())
+	cfClient.Use(recorder("first"), recorder("second"))
+	responses := []int{503, 200}
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "some-request-id", req.Header.Get("X-Request-Id"))
+		assert.Equal(t, "some-token", req.Header.Get("Authorization"))
+		body, _ := io.ReadAll(req.Body)
+		assert.Contains(t, string(body), "GetRuntime")
+		statusCode := responses[0]
+		responses = responses[1:]
+		return newResponse(statusCode, `{"data": {"runtime": {}}}`), nil
+	}).Times(2)
+
+	query := `
+query GetRuntime($name: String!) {
+	runtime(name: $name) {
+		metadata { name }
+	}
+}`
+	variables := map[string]any{"name": "some-runtime"}
+	err := cfClient.GraphqlAPI(context.Background(), query, variables, &map[string]any{})
+	assert.NoError(t, err)
+
+	// retries happen inside the chain, so every middleware is called once
+	assert.Equal(t, []string{"first:before", "second:before", "second:after", "first:after"}, calls)
+	assert.Equal(t, CallInfo{
+		Kind:          CallKindGraphql,
+		Operation:     "GetRuntime",
+		OperationType: "query",
+		Variables:     variables,
+	}, infos[0])
+
+	calls, infos = nil, nil
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).Return(newResponse(200, "ok"), nil).Once()
+	_, err = cfClient.RestAPI(context.Background(), &RequestOptions{Path: "/api/user"})
+	assert.NoError(t, err)
+	assert.Len(t, calls, 4)
+	assert.Equal(t, CallInfo{Kind: CallKindRest}, infos[0])
+}
+
+func TestCfClient_AppProxyClient_InheritsMiddleware(t *testing.T) {
+	called := false
+	cfClient, err := NewClient("https://some.host", WithMiddleware(func(next Handler) Handler {
+		return func(req *http.Request, info CallInfo) (*http.Response, error) {
+			called = true
+			return newResponse(200, "ok"), nil
+		}
+	}))
+	assert.NoError(t, err)
+
+	proxyClient := cfClient.AppProxyClient("https://app-proxy.host", false)
+	_, err = proxyClient.RestAPI(context.Background(), &RequestOptions{Path: "/api/user"})
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func Test_parseGraphqlOperation(t *testing.T) {
+	tests := []struct {
+		name              string
+		query             string
+		wantOperationType string
+		wantName          string
+	}{
+		{
+			name:              "should parse named query",
+			query:             "\nquery GetRuntime($name: String!) {\n\truntime(name: $name) { name }\n}",
+			wantOperationType: "query",
+			wantName:          "GetRuntime",
+		},
+		{
+			name:              "should parse named mutation after comments",
+			query:             "# delete it\n  mutation DeleteRuntime($name: String!) {\n\tdeleteRuntime(name: $name)\n}",
+			wantOperationType: "mutation",
+			wantName:          "DeleteRuntime",
+		},
+		{
+			name:              "should use the first field of an anonymous query",
+			query:             "query ($applicationMetadata: Object!) {\n\tpromotionTemplateByRuntime(applicationMetadata: $applicationMetadata) { name }\n}",
+			wantOperationType: "query",
+			wantName:          "promotionTemplateByRuntime",
+		},
+		{
+			name:              "should use the field name of an aliased field",
+			query:             "{ current: me { id } }",
+			wantOperationType: "query",
+			wantName:          "me",
+		},
+		{
+			name:  "should return empty values for an invalid document",
+			query: "fragment F on User { id }",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operationType, name := parseGraphqlOperation(tt.query)
+			assert.Equal(t, tt.wantOperationType, operationType)
+			assert.Equal(t, tt.wantName, name)
+		})
+	}
+}
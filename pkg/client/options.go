@@ -0,0 +1,131 @@
+package client
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Option configures a client created with NewClient
+type Option func(opt *ClientOptions)
+
+func WithToken(token string) Option {
+	return func(opt *ClientOptions) {
+		opt.Token = token
+	}
+}
+
+func WithAuthenticator(auth Authenticator) Option {
+	return func(opt *ClientOptions) {
+		opt.Authenticator = auth
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(opt *ClientOptions) {
+		opt.Client = httpClient
+	}
+}
+
+func WithGraphqlPath(graphqlPath string) Option {
+	return func(opt *ClientOptions) {
+		opt.GraphqlPath = graphqlPath
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(opt *ClientOptions) {
+		opt.UserAgent = userAgent
+	}
+}
+
+// WithTimeout sets the timeout of every http request, without changing the http client passed to WithHTTPClient
+func WithTimeout(timeout time.Duration) Option {
+	return func(opt *ClientOptions) {
+		opt.Timeout = timeout
+	}
+}
+
+func WithRetry(policy *RetryPolicy) Option {
+	return func(opt *ClientOptions) {
+		opt.Retry = policy
+	}
+}
+
+// WithInsecure skips TLS certificate verification, e.g. for on-prem installations with self-signed certificates
+func WithInsecure(insecure bool) Option {
+	return func(opt *ClientOptions) {
+		opt.Insecure = insecure
+	}
+}
+
+// parseHost validates the host is an absolute http(s) url and trims trailing slashes from its path
+func parseHost(host string) (*url.URL, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host %q: %w", host, err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid host %q: scheme must be http or https", host)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid host %q: missing hostname", host)
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	return u, nil
+}
+
+func buildHttpClient(opt *ClientOptions) (*http.Client, error) {
+	httpClient := opt.Client
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	if opt.Timeout == 0 && !opt.Insecure {
+		return httpClient, nil
+	}
+
+	// copy, so the caller's client is not modified
+	clone := *httpClient
+	if opt.Timeout != 0 {
+		clone.Timeout = opt.Timeout
+	}
+
+	if opt.Insecure {
+		transport, err := insecureTransport(clone.Transport)
+		if err != nil {
+			return nil, err
+		}
+
+		clone.Transport = transport
+	}
+
+	return &clone, nil
+}
+
+func insecureTransport(rt http.RoundTripper) (http.RoundTripper, error) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, errors.New("insecure option requires the http client to use an *http.Transport")
+	}
+
+	transport = transport.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+
+	transport.TLSClientConfig.InsecureSkipVerify = true
+	return transport, nil
+}
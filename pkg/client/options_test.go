@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/codefresh-io/go-sdk/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name        string
+		host        string
+		opts        []Option
+		wantBaseUrl string
+		wantGqlUrl  string
+		wantErr     string
+	}{
+		{
+			name:        "should create client with default graphql path",
+			host:        "https://some.host",
+			wantBaseUrl: "https://some.host",
+			wantGqlUrl:  "https://some.host/2.0/api/graphql",
+		},
+		{
+			name:        "should trim trailing slashes",
+			host:        "https://some.host/prefix//",
+			opts:        []Option{WithGraphqlPath("/custom/graphql")},
+			wantBaseUrl: "https://some.host/prefix",
+			wantGqlUrl:  "https://some.host/prefix/custom/graphql",
+		},
+		{
+			name:    "should fail on invalid url",
+			host:    "://invalid-url",
+			wantErr: "invalid host \"://invalid-url\"",
+		},
+		{
+			name:    "should fail on unsupported scheme",
+			host:    "ftp://some.host",
+			wantErr: "invalid host \"ftp://some.host\": scheme must be http or https",
+		},
+		{
+			name:    "should fail on missing hostname",
+			host:    "https://",
+			wantErr: "invalid host \"https://\": missing hostname",
+		},
+		{
+			name:    "should fail on relative url",
+			host:    "some.host",
+			wantErr: "invalid host \"some.host\": scheme must be http or https",
+		},
+		{
+			name:    "should fail when insecure is used with a custom transport",
+			host:    "https://some.host",
+			opts:    []Option{WithHTTPClient(&http.Client{Transport: mocks.NewMockRoundTripper(t)}), WithInsecure(true)},
+			wantErr: "insecure option requires the http client to use an *http.Transport",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient(tt.host, tt.opts...)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Nil(t, c)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantBaseUrl, c.baseUrl.String())
+			assert.Equal(t, tt.wantGqlUrl, c.gqlUrl.String())
+		})
+	}
+}
+
+func TestNewClient_Options(t *testing.T) {
+	httpClient := &http.Client{}
+	policy := DefaultRetryPolicy()
+	c, err := NewClient("https://some.host",
+		WithToken("some-token"),
+		WithHTTPClient(httpClient),
+		WithTimeout(5*time.Second),
+		WithInsecure(true),
+		WithRetry(policy),
+		WithUserAgent("some-agent/1.0"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "some-token", c.token)
+	assert.Equal(t, policy, c.retry)
+	assert.Equal(t, "some-agent/1.0", c.userAgent)
+	assert.Equal(t, 5*time.Second, c.client.Timeout)
+	assert.True(t, c.client.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify)
+
+	// the caller's client is not modified
+	assert.Equal(t, time.Duration(0), httpClient.Timeout)
+	assert.Nil(t, httpClient.Transport)
+}
+
+func TestNewClient_UserAgent(t *testing.T) {
+	mockRT := mocks.NewMockRoundTripper(t)
+	c, err := NewClient("https://some.host",
+		WithToken("some-token"),
+		WithHTTPClient(&http.Client{Transport: mockRT}),
+		WithUserAgent("some-agent/1.0"),
+	)
+	assert.NoError(t, err)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "some-agent/1.0", req.Header.Get("User-Agent"))
+		assert.Equal(t, "some-token", req.Header.Get("Authorization"))
+		return newResponse(200, "ok"), nil
+	})
+
+	_, err = c.RestAPI(context.Background(), &RequestOptions{Path: "/api/user"})
+	assert.NoError(t, err)
+}
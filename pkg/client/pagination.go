@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+type (
+	// PageArgs are the cursor pagination arguments of a graphql slice query (SlicePaginationArgs)
+	PageArgs struct {
+		After *string `json:"after,omitempty"`
+		First *int    `json:"first,omitempty"`
+	}
+
+	// PageOptions configures how a slice is paged
+	PageOptions struct {
+		// PageSize is the number of items requested per page (default: the server's page size)
+		PageSize int
+		// MaxItems stops paging once this many items were returned (default: no limit)
+		MaxItems int
+	}
+
+	PageOption func(opt *PageOptions)
+
+	// PageFetcher returns a single page of a graphql slice. S is any slice type (e.g. *platform.RuntimeSlice)
+	// with an `Edges []*XEdge` field, where each edge has a `Node *X` field, and a `PageInfo` field with
+	// `EndCursor *string` and `HasNextPage bool` fields.
+	PageFetcher[S any] func(ctx context.Context, args PageArgs) (S, error)
+)
+
+// ErrStopPaging can be returned by a page callback to stop paging without an error
+var ErrStopPaging = errors.New("stop paging")
+
+func WithPageSize(pageSize int) PageOption {
+	return func(opt *PageOptions) {
+		opt.PageSize = pageSize
+	}
+}
+
+func WithMaxItems(maxItems int) PageOption {
+	return func(opt *PageOptions) {
+		opt.MaxItems = maxItems
+	}
+}
+
+// Paginate fetches all pages of a slice and returns their nodes
+func Paginate[N any, S any](ctx context.Context, fetch PageFetcher[S], opts ...PageOption) ([]N, error) {
+	nodes := make([]N, 0)
+	err := ForEachPage(ctx, fetch, func(page []N) error {
+		nodes = append(nodes, page...)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// ForEachPage fetches the pages of a slice one by one, and calls fn with the nodes of each page.
+// Paging stops when there is no next page, when MaxItems is reached, or when fn returns an error.
+func ForEachPage[N any, S any](ctx context.Context, fetch PageFetcher[S], fn func(page []N) error, opts ...PageOption) error {
+	opt := &PageOptions{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	args := PageArgs{}
+	count := 0
+	for {
+		if opt.PageSize > 0 {
+			first := opt.PageSize
+			if opt.MaxItems > 0 {
+				first = min(first, opt.MaxItems-count)
+			}
+
+			args.First = &first
+		}
+
+		slice, err := fetch(ctx, args)
+		if err != nil {
+			return err
+		}
+
+		page, endCursor, hasNextPage, err := sliceNodes[N](slice)
+		if err != nil {
+			return err
+		}
+
+		if opt.MaxItems > 0 && count+len(page) > opt.MaxItems {
+			page = page[:opt.MaxItems-count]
+		}
+
+		count += len(page)
+		if err = fn(page); err != nil {
+			if errors.Is(err, ErrStopPaging) {
+				return nil
+			}
+
+			return err
+		}
+
+		if !hasNextPage || endCursor == nil || (opt.MaxItems > 0 && count >= opt.MaxItems) {
+			return nil
+		}
+
+		if args.After != nil && *args.After == *endCursor {
+			return fmt.Errorf("failed paging: cursor %q did not advance", *endCursor)
+		}
+
+		args.After = endCursor
+	}
+}
+
+// sliceNodes extracts the nodes and page info of a slice
+func sliceNodes[N any](slice any) (nodes []N, endCursor *string, hasNextPage bool, err error) {
+	v := reflect.Indirect(reflect.ValueOf(slice))
+	if !v.IsValid() {
+		return nil, nil, false, nil
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil, nil, false, fmt.Errorf("invalid slice type %T", slice)
+	}
+
+	edges := v.FieldByName("Edges")
+	if !edges.IsValid() || edges.Kind() != reflect.Slice {
+		return nil, nil, false, fmt.Errorf("invalid slice type %T: missing Edges", slice)
+	}
+
+	nodes = make([]N, 0, edges.Len())
+	for i := 0; i < edges.Len(); i++ {
+		edge := reflect.Indirect(edges.Index(i))
+		if !edge.IsValid() {
+			continue
+		}
+
+		node := edge.FieldByName("Node")
+		if !node.IsValid() {
+			return nil, nil, false, fmt.Errorf("invalid slice type %T: missing Edges.Node", slice)
+		}
+
+		node = reflect.Indirect(node)
+		if !node.IsValid() {
+			continue
+		}
+
+		n, ok := node.Interface().(N)
+		if !ok {
+			return nil, nil, false, fmt.Errorf("invalid slice type %T: node is %s, not %T", slice, node.Type(), n)
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	pageInfo := reflect.Indirect(v.FieldByName("PageInfo"))
+	if !pageInfo.IsValid() || pageInfo.Kind() != reflect.Struct {
+		return nodes, nil, false, nil
+	}
+
+	cursor := reflect.Indirect(pageInfo.FieldByName("EndCursor"))
+	if cursor.IsValid() && cursor.Kind() == reflect.String {
+		c := cursor.String()
+		endCursor = &c
+	}
+
+	hasNext := pageInfo.FieldByName("HasNextPage")
+	return nodes, endCursor, hasNext.IsValid() && hasNext.Bool(), nil
+}
@@ -0,0 +1,41 @@
+//go:build go1.23
+
+package client
+
+import (
+	"context"
+	"iter"
+)
+
+// All returns an iterator over the nodes of all pages of a slice, fetching the next page only when it is reached.
+// Iteration stops after the first error.
+func All[N any, S any](ctx context.Context, fetch PageFetcher[S], opts ...PageOption) iter.Seq2[N, error] {
+	return PagesSeq(func(fn func(page []N) error) error {
+		return ForEachPage(ctx, fetch, fn, opts...)
+	})
+}
+
+// PagesSeq adapts a function that calls fn with each page of a list, like the ListPages methods of the
+// graphql and rest apis, to an iterator over the items of the list. Iteration stops after the first error.
+//
+//	pages := func(fn func(page []rest.Pipeline) error) error {
+//		return rest.NewRestV2Client(cf.InternalClient()).Pipeline().ListPages(ctx, opt, fn)
+//	}
+//	for pipeline, err := range client.PagesSeq(pages) {
+func PagesSeq[T any](forEachPage func(fn func(page []T) error) error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		err := forEachPage(func(page []T) error {
+			for _, item := range page {
+				if !yield(item, nil) {
+					return ErrStopPaging
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
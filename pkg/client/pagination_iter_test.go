@@ -0,0 +1,35 @@
+//go:build go1.23
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAll(t *testing.T) {
+	var requests []PageArgs
+	ids := []int{}
+	for node, err := range All[testNode](context.Background(), testFetcher(25, 10, &requests)) {
+		assert.NoError(t, err)
+		ids = append(ids, node.ID)
+		if node.ID == 14 {
+			break
+		}
+	}
+
+	// the third page is never fetched
+	assert.Len(t, ids, 15)
+	assert.Len(t, requests, 2)
+
+	someErr := errors.New("some error")
+	fetch := func(_ context.Context, _ PageArgs) (*testSlice, error) {
+		return nil, someErr
+	}
+	for _, err := range All[testNode](context.Background(), fetch) {
+		assert.ErrorIs(t, err, someErr)
+	}
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	testNode struct {
+		ID int
+	}
+
+	testEdge struct {
+		Node *testNode
+	}
+
+	testPageInfo struct {
+		EndCursor   *string
+		HasNextPage bool
+	}
+
+	testSlice struct {
+		Edges    []*testEdge
+		PageInfo *testPageInfo
+	}
+)
+
+// testFetcher serves total nodes, in pages of pageSize (or args.First, when set)
+func testFetcher(total, pageSize int, requests *[]PageArgs) PageFetcher[*testSlice] {
+	return func(_ context.Context, args PageArgs) (*testSlice, error) {
+		*requests = append(*requests, args)
+		start := 0
+		if args.After != nil {
+			fmt.Sscanf(*args.After, "cursor-%d", &start)
+		}
+
+		size := pageSize
+		if args.First != nil {
+			size = *args.First
+		}
+
+		slice := &testSlice{PageInfo: &testPageInfo{}}
+		end := min(start+size, total)
+		for i := start; i < end; i++ {
+			slice.Edges = append(slice.Edges, &testEdge{Node: &testNode{ID: i}})
+		}
+
+		cursor := fmt.Sprintf("cursor-%d", end)
+		slice.PageInfo.EndCursor = &cursor
+		slice.PageInfo.HasNextPage = end < total
+		return slice, nil
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        int
+		opts         []PageOption
+		wantCount    int
+		wantRequests int
+		wantFirst    []int
+	}{
+		{
+			name:         "should fetch all pages",
+			total:        25,
+			wantCount:    25,
+			wantRequests: 3,
+		},
+		{
+			name:         "should request the page size",
+			total:        25,
+			opts:         []PageOption{WithPageSize(5)},
+			wantCount:    25,
+			wantRequests: 5,
+			wantFirst:    []int{5, 5, 5, 5, 5},
+		},
+		{
+			name:         "should stop at max items",
+			total:        25,
+			opts:         []PageOption{WithPageSize(4), WithMaxItems(10)},
+			wantCount:    10,
+			wantRequests: 3,
+			wantFirst:    []int{4, 4, 2},
+		},
+		{
+			name:         "should trim the last page to max items",
+			total:        25,
+			opts:         []PageOption{WithMaxItems(12)},
+			wantCount:    12,
+			wantRequests: 2,
+		},
+		{
+			name:         "should return an empty list",
+			wantCount:    0,
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []PageArgs
+			nodes, err := Paginate[testNode](context.Background(), testFetcher(tt.total, 10, &requests), tt.opts...)
+			assert.NoError(t, err)
+			assert.Len(t, nodes, tt.wantCount)
+			for i := range nodes {
+				assert.Equal(t, i, nodes[i].ID)
+			}
+
+			assert.Len(t, requests, tt.wantRequests)
+			assert.Nil(t, requests[0].After)
+			if tt.wantFirst != nil {
+				first := make([]int, len(requests))
+				for i := range requests {
+					first[i] = *requests[i].First
+				}
+
+				assert.Equal(t, tt.wantFirst, first)
+			}
+		})
+	}
+}
+
+func TestForEachPage(t *testing.T) {
+	var requests []PageArgs
+	someErr := errors.New("some error")
+	pages := 0
+	err := ForEachPage(context.Background(), testFetcher(25, 10, &requests), func(page []testNode) error {
+		pages++
+		if pages == 2 {
+			return someErr
+		}
+
+		return nil
+	})
+	assert.ErrorIs(t, err, someErr)
+	assert.Len(t, requests, 2)
+}
+
+func TestPaginate_Errors(t *testing.T) {
+	t.Run("should fail when the cursor does not advance", func(t *testing.T) {
+		cursor := "same"
+		_, err := Paginate[testNode](context.Background(), func(_ context.Context, _ PageArgs) (*testSlice, error) {
+			return &testSlice{
+				Edges:    []*testEdge{{Node: &testNode{}}},
+				PageInfo: &testPageInfo{EndCursor: &cursor, HasNextPage: true},
+			}, nil
+		})
+		assert.EqualError(t, err, `failed paging: cursor "same" did not advance`)
+	})
+
+	t.Run("should fail on a type without edges", func(t *testing.T) {
+		_, err := Paginate[testNode](context.Background(), func(_ context.Context, _ PageArgs) (*testNode, error) {
+			return &testNode{}, nil
+		})
+		assert.EqualError(t, err, "invalid slice type *client.testNode: missing Edges")
+	})
+
+	t.Run("should fail on a node type mismatch", func(t *testing.T) {
+		var requests []PageArgs
+		_, err := Paginate[string](context.Background(), testFetcher(1, 10, &requests))
+		assert.EqualError(t, err, "invalid slice type *client.testSlice: node is client.testNode, not string")
+	})
+}
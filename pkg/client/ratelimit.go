@@ -0,0 +1,305 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// EndpointClass groups the calls that share a rate limit
+	EndpointClass string
+
+	// RateLimit limits the calls of a single endpoint class. Zero values mean no limit.
+	RateLimit struct {
+		// RequestsPerSecond is the rate at which the token bucket is refilled
+		RequestsPerSecond float64
+		// Burst is the size of the token bucket (default: 1)
+		Burst int
+		// MaxInFlight is the maximum number of requests waiting for a response
+		MaxInFlight int
+	}
+
+	// RateLimits configures the rate limiter of a client, per endpoint class.
+	// A nil class is not limited, but still pauses when the platform asks to slow down.
+	RateLimits struct {
+		Rest     *RateLimit
+		Graphql  *RateLimit
+		AppProxy *RateLimit
+	}
+
+	// RateLimiterState is a snapshot of the limiter of an endpoint class
+	RateLimiterState struct {
+		Class             EndpointClass
+		RequestsPerSecond float64
+		Burst             int
+		// Tokens is the number of requests that can be sent without waiting
+		Tokens      float64
+		InFlight    int
+		MaxInFlight int
+		// PausedUntil is set when the platform returned Retry-After or exhausted X-RateLimit-* headers
+		PausedUntil time.Time
+		// Limit, Remaining and Reset are the last X-RateLimit-* values, Limit and Remaining are -1 when unknown
+		Limit     int
+		Remaining int
+		Reset     time.Time
+	}
+
+	// RateLimiter limits the rate and concurrency of the requests of a client, and of the app-proxy clients created from it
+	RateLimiter struct {
+		classes map[EndpointClass]*classLimiter
+	}
+
+	classLimiter struct {
+		mu          sync.Mutex
+		class       EndpointClass
+		limit       RateLimit
+		tokens      float64
+		last        time.Time
+		inFlight    chan struct{}
+		pausedUntil time.Time
+		rateLimit   int
+		remaining   int
+		reset       time.Time
+	}
+
+	// releaseOnClose releases an in-flight slot when the response body is closed
+	releaseOnClose struct {
+		io.ReadCloser
+		once    sync.Once
+		release func()
+	}
+)
+
+const (
+	EndpointClassRest     EndpointClass = "rest"
+	EndpointClassGraphql  EndpointClass = "graphql"
+	EndpointClassAppProxy EndpointClass = "app-proxy"
+)
+
+// WithRateLimits limits the rate and concurrency of the requests, per endpoint class
+func WithRateLimits(limits RateLimits) Option {
+	return func(opt *ClientOptions) {
+		opt.RateLimits = &limits
+	}
+}
+
+// NewRateLimiter creates a rate limiter for the given limits
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	return &RateLimiter{
+		classes: map[EndpointClass]*classLimiter{
+			EndpointClassRest:     newClassLimiter(EndpointClassRest, limits.Rest),
+			EndpointClassGraphql:  newClassLimiter(EndpointClassGraphql, limits.Graphql),
+			EndpointClassAppProxy: newClassLimiter(EndpointClassAppProxy, limits.AppProxy),
+		},
+	}
+}
+
+func newClassLimiter(class EndpointClass, limit *RateLimit) *classLimiter {
+	l := &classLimiter{
+		class:     class,
+		rateLimit: -1,
+		remaining: -1,
+	}
+	if limit == nil {
+		return l
+	}
+
+	l.limit = *limit
+	if l.limit.Burst < 1 {
+		l.limit.Burst = 1
+	}
+
+	l.tokens = float64(l.limit.Burst)
+	l.last = time.Now()
+	if l.limit.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, l.limit.MaxInFlight)
+	}
+
+	return l
+}
+
+// RateLimiter returns the rate limiter of the client, or nil if rate limiting is disabled
+func (c *CfClient) RateLimiter() *RateLimiter {
+	return c.limiter
+}
+
+// State returns the current state of the limiter of each endpoint class, or an empty map for a nil limiter
+func (r *RateLimiter) State() map[EndpointClass]RateLimiterState {
+	if r == nil {
+		return map[EndpointClass]RateLimiterState{}
+	}
+
+	states := make(map[EndpointClass]RateLimiterState, len(r.classes))
+	for class, l := range r.classes {
+		states[class] = l.state()
+	}
+
+	return states
+}
+
+// acquire waits until a request of the class can be sent, and returns a function that releases its in-flight slot
+func (r *RateLimiter) acquire(ctx context.Context, class EndpointClass) (func(), error) {
+	if r == nil {
+		return func() {}, nil
+	}
+
+	return r.classes[class].acquire(ctx)
+}
+
+// observe adapts the limiter of the class to the rate limit headers of a response
+func (r *RateLimiter) observe(class EndpointClass, res *http.Response) {
+	if r == nil || res == nil {
+		return
+	}
+
+	r.classes[class].observe(res)
+}
+
+func (l *classLimiter) acquire(ctx context.Context) (func(), error) {
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	release := func() {
+		if l.inFlight != nil {
+			<-l.inFlight
+		}
+	}
+	for {
+		wait := l.reserve()
+		if wait == 0 {
+			return release, nil
+		}
+
+		if err := sleepContext(ctx, wait); err != nil {
+			release()
+			return nil, err
+		}
+	}
+}
+
+// reserve takes a token, or returns how long to wait before trying again
+func (l *classLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+
+	if l.limit.RequestsPerSecond <= 0 {
+		return 0
+	}
+
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.limit.RequestsPerSecond, float64(l.limit.Burst))
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - l.tokens) / l.limit.RequestsPerSecond * float64(time.Second))
+}
+
+func (l *classLimiter) observe(res *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if limit, err := strconv.Atoi(res.Header.Get("X-RateLimit-Limit")); err == nil {
+		l.rateLimit = limit
+	}
+
+	if remaining, err := strconv.Atoi(res.Header.Get("X-RateLimit-Remaining")); err == nil {
+		l.remaining = remaining
+	}
+
+	if reset, ok := parseRateLimitReset(res.Header.Get("X-RateLimit-Reset"), now); ok {
+		l.reset = reset
+	}
+
+	if l.remaining == 0 && l.reset.After(now) {
+		l.pause(l.reset)
+	}
+
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable {
+		if delay, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			l.pause(now.Add(delay))
+		}
+	}
+}
+
+func (l *classLimiter) pause(until time.Time) {
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+func (l *classLimiter) state() RateLimiterState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tokens := l.tokens
+	if l.limit.RequestsPerSecond > 0 {
+		tokens = min(tokens+time.Since(l.last).Seconds()*l.limit.RequestsPerSecond, float64(l.limit.Burst))
+	}
+
+	state := RateLimiterState{
+		Class:             l.class,
+		RequestsPerSecond: l.limit.RequestsPerSecond,
+		Burst:             l.limit.Burst,
+		Tokens:            tokens,
+		InFlight:          len(l.inFlight),
+		MaxInFlight:       l.limit.MaxInFlight,
+		Limit:             l.rateLimit,
+		Remaining:         l.remaining,
+		Reset:             l.reset,
+	}
+	if time.Now().Before(l.pausedUntil) {
+		state.PausedUntil = l.pausedUntil
+	}
+
+	return state
+}
+
+// parseRateLimitReset parses X-RateLimit-Reset, which is either a unix timestamp or a number of seconds from now
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, false
+	}
+
+	// values larger than a year are unix timestamps
+	if seconds > 365*24*60*60 {
+		return time.Unix(seconds, 0), true
+	}
+
+	return now.Add(time.Duration(seconds) * time.Second), true
+}
+
+func (r *releaseOnClose) Close() error {
+	r.once.Do(r.release)
+	return r.ReadCloser.Close()
+}
+
+// endpointClass returns the limiter class of a call made by the client
+func (c *CfClient) endpointClass(info CallInfo) EndpointClass {
+	if c.appProxy {
+		return EndpointClassAppProxy
+	}
+
+	if info.Kind == CallKindGraphql {
+		return EndpointClassGraphql
+	}
+
+	return EndpointClassRest
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/codefresh-io/go-sdk/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newRateLimitedClient(t *testing.T, limits RateLimits) (*CfClient, *mocks.MockRoundTripper) {
+	mockRT := mocks.NewMockRoundTripper(t)
+	c, err := NewClient("https://some.host",
+		WithToken("some-token"),
+		WithHTTPClient(&http.Client{Transport: mockRT}),
+		WithRateLimits(limits),
+	)
+	assert.NoError(t, err)
+	return c, mockRT
+}
+
+func TestRateLimiter_TokenBucket(t *testing.T) {
+	c, mockRT := newRateLimitedClient(t, RateLimits{
+		Rest: &RateLimit{RequestsPerSecond: 100, Burst: 1},
+	})
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(_ *http.Request) (*http.Response, error) {
+		return newResponse(200, "{}"), nil
+	}).Times(4)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := c.RestAPI(context.Background(), &RequestOptions{Path: "/api/user"})
+		assert.NoError(t, err)
+	}
+
+	// the first request uses the burst, the others wait 10ms each
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	// graphql calls are not limited by the rest limit
+	err := c.GraphqlAPI(context.Background(), "query Me { me { id } }", nil, &map[string]any{})
+	assert.NoError(t, err)
+
+	states := c.RateLimiter().State()
+	assert.Equal(t, float64(100), states[EndpointClassRest].RequestsPerSecond)
+	assert.Equal(t, 1, states[EndpointClassRest].Burst)
+	assert.Less(t, states[EndpointClassRest].Tokens, float64(1))
+	assert.Equal(t, float64(0), states[EndpointClassGraphql].RequestsPerSecond)
+}
+
+func TestRateLimiter_MaxInFlight(t *testing.T) {
+	c, mockRT := newRateLimitedClient(t, RateLimits{
+		Rest: &RateLimit{MaxInFlight: 1},
+	})
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(_ *http.Request) (*http.Response, error) {
+		return newResponse(200, "ok"), nil
+	})
+
+	res, err := c.NativeRestAPI(context.Background(), &RequestOptions{Path: "/api/user"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.RateLimiter().State()[EndpointClassRest].InFlight)
+
+	// the slot is held until the body of the first response is closed
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = c.RestAPI(ctx, &RequestOptions{Path: "/api/user"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	res.Body.Close()
+	assert.Equal(t, 0, c.RateLimiter().State()[EndpointClassRest].InFlight)
+	_, err = c.RestAPI(context.Background(), &RequestOptions{Path: "/api/user"})
+	assert.NoError(t, err)
+}
+
+func TestRateLimiter_Adapts(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		headers       map[string]string
+		wantPaused    bool
+		wantLimit     int
+		wantRemaining int
+	}{
+		{
+			name:          "should pause on Retry-After",
+			statusCode:    429,
+			headers:       map[string]string{"Retry-After": "1"},
+			wantPaused:    true,
+			wantLimit:     -1,
+			wantRemaining: -1,
+		},
+		{
+			name:          "should pause when the remaining requests are exhausted",
+			statusCode:    200,
+			headers:       map[string]string{"X-RateLimit-Limit": "100", "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1"},
+			wantPaused:    true,
+			wantLimit:     100,
+			wantRemaining: 0,
+		},
+		{
+			name:          "should not pause while requests remain",
+			statusCode:    200,
+			headers:       map[string]string{"X-RateLimit-Limit": "100", "X-RateLimit-Remaining": "5", "X-RateLimit-Reset": "1"},
+			wantLimit:     100,
+			wantRemaining: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mockRT := newRateLimitedClient(t, RateLimits{})
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(_ *http.Request) (*http.Response, error) {
+				res := newResponse(tt.statusCode, "ok")
+				for k, v := range tt.headers {
+					res.Header.Set(k, v)
+				}
+
+				return res, nil
+			}).Once()
+
+			_, _ = c.RestAPI(context.Background(), &RequestOptions{Path: "/api/user"})
+			state := c.RateLimiter().State()[EndpointClassRest]
+			assert.Equal(t, tt.wantPaused, !state.PausedUntil.IsZero())
+			assert.Equal(t, tt.wantLimit, state.Limit)
+			assert.Equal(t, tt.wantRemaining, state.Remaining)
+			if !tt.wantPaused {
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			_, err := c.RestAPI(ctx, &RequestOptions{Path: "/api/user"})
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		})
+	}
+}
+
+func TestRateLimiter_AppProxyClient(t *testing.T) {
+	c, _ := newRateLimitedClient(t, RateLimits{
+		AppProxy: &RateLimit{RequestsPerSecond: 5},
+	})
+	proxyClient := c.AppProxyClient("https://app-proxy.host", false)
+	assert.Same(t, c.RateLimiter(), proxyClient.RateLimiter())
+	assert.Equal(t, EndpointClassAppProxy, proxyClient.endpointClass(CallInfo{Kind: CallKindGraphql}))
+	assert.Equal(t, EndpointClassGraphql, c.endpointClass(CallInfo{Kind: CallKindGraphql}))
+}
+
+func TestRateLimiter_State_Disabled(t *testing.T) {
+	c := NewCfClient("https://some.host", "some-token", "", &http.Client{})
+	assert.Nil(t, c.RateLimiter())
+	assert.Empty(t, c.RateLimiter().State())
+}
+
+func Test_parseRateLimitReset(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Time
+		wantOk bool
+	}{
+		{
+			name:   "should parse seconds from now",
+			value:  "30",
+			want:   now.Add(30 * time.Second),
+			wantOk: true,
+		},
+		{
+			name:   "should parse unix timestamp",
+			value:  "1700000060",
+			want:   time.Unix(1700000060, 0),
+			wantOk: true,
+		},
+		{
+			name:  "should ignore invalid value",
+			value: "soon",
+		},
+		{
+			name: "should ignore empty value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRateLimitReset(tt.value, now)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type (
+	// RetryPolicy controls how failed calls are retried.
+	// A nil policy disables retries.
+	RetryPolicy struct {
+		// MaxAttempts is the total number of attempts, including the first one
+		MaxAttempts int
+		// InitialBackoff is the delay before the first retry, doubled on every following retry
+		InitialBackoff time.Duration
+		// MaxBackoff caps the computed backoff (does not apply to Retry-After)
+		MaxBackoff time.Duration
+		// Jitter is the fraction (0-1) of each backoff that is randomized
+		Jitter float64
+		// RetryableStatusCodes overrides the default list of transient status codes
+		RetryableStatusCodes []int
+		// RetryNonIdempotent allows retrying POST and PATCH requests and graphql mutations
+		RetryNonIdempotent bool
+	}
+)
+
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryPolicy returns a policy that makes up to 4 attempts, with a backoff between 500ms and 10s
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Jitter:         0.5,
+	}
+}
+
+func (p *RetryPolicy) maxAttempts(idempotent bool) int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+
+	if !idempotent && !p.RetryNonIdempotent {
+		return 1
+	}
+
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		// transport errors (connection reset, EOF, dial errors etc.)
+		return true
+	}
+
+	codes := p.RetryableStatusCodes
+	if codes == nil {
+		codes = defaultRetryableStatusCodes
+	}
+
+	return slices.Contains(codes, res.StatusCode)
+}
+
+// backoff returns the delay before the next attempt, preferring the server's Retry-After header
+func (p *RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if d, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+
+	d := time.Duration(float64(p.InitialBackoff) * math.Pow(2, float64(attempt-1)))
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 && d > 0 {
+		jitter := min(p.Jitter, 1)
+		d -= time.Duration(rand.Float64() * jitter * float64(d))
+	}
+
+	return d
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+
+	return 0, false
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	}
+
+	return false
+}
+
+// discardResponse drains and closes the body of a response that will not be returned to the caller,
+// so the underlying connection can be reused
+func discardResponse(res *http.Response) {
+	if res == nil || res.Body == nil {
+		return
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+	res.Body.Close()
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
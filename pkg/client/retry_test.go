@@ -0,0 +1,336 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codefresh-io/go-sdk/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newRetryClient(t *testing.T, policy *RetryPolicy) (*CfClient, *mocks.MockRoundTripper) {
+	mockRT := mocks.NewMockRoundTripper(t)
+	cfClient := NewCfClientWithOptions(&ClientOptions{
+		Host:        "https://some.host",
+		Token:       "some-token",
+		GraphqlPath: "grpahql-path",
+		Client:      &http.Client{Transport: mockRT},
+		Retry:       policy,
+	})
+	return cfClient, mockRT
+}
+
+func testRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	}
+}
+
+func newResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestCfClient_RestAPI_Retry(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    *RetryPolicy
+		opt       *RequestOptions
+		responses []func() (*http.Response, error)
+		wantCalls int
+		wantData  string
+		wantErr   string
+	}{
+		{
+			name:   "should retry GET on 503 and succeed",
+			policy: testRetryPolicy(),
+			opt:    &RequestOptions{Path: "/api/user"},
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return newResponse(503, "unavailable"), nil },
+				func() (*http.Response, error) { return newResponse(200, "ok"), nil },
+			},
+			wantCalls: 2,
+			wantData:  "ok",
+		},
+		{
+			name:   "should retry on transport error",
+			policy: testRetryPolicy(),
+			opt:    &RequestOptions{Path: "/api/user"},
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return nil, errors.New("connection reset by peer") },
+				func() (*http.Response, error) { return newResponse(200, "ok"), nil },
+			},
+			wantCalls: 2,
+			wantData:  "ok",
+		},
+		{
+			name:   "should stop after max attempts",
+			policy: testRetryPolicy(),
+			opt:    &RequestOptions{Path: "/api/user"},
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return newResponse(502, "bad gateway"), nil },
+				func() (*http.Response, error) { return newResponse(502, "bad gateway"), nil },
+				func() (*http.Response, error) { return newResponse(502, "bad gateway"), nil },
+			},
+			wantCalls: 3,
+			wantErr:   "API error: Bad Gateway: bad gateway",
+		},
+		{
+			name:   "should not retry non-transient status codes",
+			policy: testRetryPolicy(),
+			opt:    &RequestOptions{Path: "/api/user"},
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return newResponse(500, "boom"), nil },
+			},
+			wantCalls: 1,
+			wantErr:   "API error: Internal Server Error: boom",
+		},
+		{
+			name:   "should not retry POST by default",
+			policy: testRetryPolicy(),
+			opt:    &RequestOptions{Path: "/api/pipelines", Method: "POST", Body: map[string]string{"a": "b"}},
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return newResponse(503, "unavailable"), nil },
+			},
+			wantCalls: 1,
+			wantErr:   "API error: Service Unavailable: unavailable",
+		},
+		{
+			name:   "should retry POST marked as idempotent",
+			policy: testRetryPolicy(),
+			opt:    &RequestOptions{Path: "/api/argo-agent/x/heartbeat", Method: "POST", Body: map[string]string{"a": "b"}, Idempotent: true},
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return newResponse(429, "slow down"), nil },
+				func() (*http.Response, error) { return newResponse(200, "ok"), nil },
+			},
+			wantCalls: 2,
+			wantData:  "ok",
+		},
+		{
+			name: "should retry POST when policy allows non-idempotent requests",
+			policy: &RetryPolicy{
+				MaxAttempts:        2,
+				InitialBackoff:     time.Millisecond,
+				RetryNonIdempotent: true,
+			},
+			opt: &RequestOptions{Path: "/api/pipelines", Method: "POST"},
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return newResponse(504, "timeout"), nil },
+				func() (*http.Response, error) { return newResponse(200, "ok"), nil },
+			},
+			wantCalls: 2,
+			wantData:  "ok",
+		},
+		{
+			name:   "should not retry GET marked as not idempotent",
+			policy: testRetryPolicy(),
+			opt:    &RequestOptions{Path: "/api/builds/rebuild/x", NotIdempotent: true},
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return newResponse(503, "unavailable"), nil },
+			},
+			wantCalls: 1,
+			wantErr:   "API error: Service Unavailable: unavailable",
+		},
+		{
+			name:   "should not retry without a policy",
+			policy: nil,
+			opt:    &RequestOptions{Path: "/api/user"},
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return newResponse(503, "unavailable"), nil },
+			},
+			wantCalls: 1,
+			wantErr:   "API error: Service Unavailable: unavailable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := newRetryClient(t, tt.policy)
+			calls := 0
+			var bodies []string
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				b, _ := io.ReadAll(req.Body)
+				bodies = append(bodies, string(b))
+				calls++
+				return tt.responses[calls-1]()
+			}).Times(tt.wantCalls)
+
+			data, err := cfClient.RestAPI(context.Background(), tt.opt)
+			assert.Equal(t, tt.wantCalls, calls)
+			for _, b := range bodies {
+				assert.Equal(t, bodies[0], b, "request body should be re-sent on every attempt")
+			}
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantData, string(data))
+		})
+	}
+}
+
+func TestCfClient_GraphqlAPI_Retry(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantCalls int
+	}{
+		{
+			name:      "should retry queries",
+			query:     "\nquery Me {\n\tme { id }\n}",
+			wantCalls: 2,
+		},
+		{
+			name:      "should not retry mutations",
+			query:     "\nmutation DeleteRuntime($name: String!) {\n\tdeleteRuntime(name: $name)\n}",
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := newRetryClient(t, testRetryPolicy())
+			calls := 0
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				calls++
+				if calls == 1 {
+					return newResponse(503, "unavailable"), nil
+				}
+
+				return newResponse(200, `{"data": {}}`), nil
+			}).Times(tt.wantCalls)
+
+			var result map[string]any
+			_ = cfClient.GraphqlAPI(context.Background(), tt.query, nil, &result)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestCfClient_Download_Retry(t *testing.T) {
+	cfClient, mockRT := newRetryClient(t, testRetryPolicy())
+	middlewareCalls := 0
+	cfClient.Use(func(next Handler) Handler {
+		return func(req *http.Request, info CallInfo) (*http.Response, error) {
+			middlewareCalls++
+			return next(req, info)
+		}
+	})
+	calls := 0
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		calls++
+		assert.Empty(t, req.Header.Get("Authorization"))
+		if calls == 1 {
+			return newResponse(503, "unavailable"), nil
+		}
+
+		return newResponse(200, "some content"), nil
+	}).Times(2)
+
+	body, err := cfClient.Download(context.Background(), "https://storage.host/some/file")
+	assert.NoError(t, err)
+	defer body.Close()
+	got, _ := io.ReadAll(body)
+	assert.Equal(t, "some content", string(got))
+	assert.Equal(t, 1, middlewareCalls)
+}
+
+func TestCfClient_Retry_ContextCanceled(t *testing.T) {
+	cfClient, mockRT := newRetryClient(t, &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Hour,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		cancel()
+		return newResponse(503, "unavailable"), nil
+	}).Once()
+
+	_, err := cfClient.RestAPI(ctx, &RequestOptions{Path: "/api/user"})
+	assert.Error(t, err)
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := &RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter string
+		want       time.Duration
+	}{
+		{
+			name:    "should use initial backoff on first retry",
+			attempt: 1,
+			want:    100 * time.Millisecond,
+		},
+		{
+			name:    "should double backoff on each retry",
+			attempt: 3,
+			want:    400 * time.Millisecond,
+		},
+		{
+			name:    "should cap backoff at max",
+			attempt: 10,
+			want:    time.Second,
+		},
+		{
+			name:       "should prefer Retry-After seconds",
+			attempt:    1,
+			retryAfter: "3",
+			want:       3 * time.Second,
+		},
+		{
+			name:       "should ignore invalid Retry-After",
+			attempt:    1,
+			retryAfter: "soon",
+			want:       100 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := newResponse(429, "")
+			if tt.retryAfter != "" {
+				res.Header.Set("Retry-After", tt.retryAfter)
+			}
+
+			assert.Equal(t, tt.want, p.backoff(tt.attempt, res))
+		})
+	}
+}
+
+func TestRetryPolicy_backoff_Jitter(t *testing.T) {
+	p := &RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		Jitter:         0.5,
+	}
+	for i := 0; i < 100; i++ {
+		d := p.backoff(1, nil)
+		assert.GreaterOrEqual(t, d, 50*time.Millisecond)
+		assert.LessOrEqual(t, d, 100*time.Millisecond)
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	d, ok := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Minute.Seconds(), d.Seconds(), 2)
+
+	_, ok = parseRetryAfter("")
+	assert.False(t, ok)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	ap "github.com/codefresh-io/go-sdk/pkg/appproxy"
 	"github.com/codefresh-io/go-sdk/pkg/client"
@@ -25,10 +26,18 @@ type (
 	}
 
 	ClientOptions struct {
-		Token       string
-		Host        string
-		Client      *http.Client
-		GraphqlPath string
+		Token string
+		// Authenticator overrides Token, when set
+		Authenticator client.Authenticator
+		Host          string
+		Client        *http.Client
+		GraphqlPath   string
+		Retry         *client.RetryPolicy
+		UserAgent     string
+		// Timeout overrides the timeout of Client, when set
+		Timeout time.Duration
+		// Insecure skips TLS certificate verification
+		Insecure bool
 	}
 
 	codefresh struct {
@@ -36,9 +45,37 @@ type (
 	}
 )
 
+// NewClient creates a client for the platform at host, which must be an absolute http(s) url
+func NewClient(host string, opts ...client.Option) (Codefresh, error) {
+	cfClient, err := client.NewClient(host, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating client: %w", err)
+	}
+
+	return &codefresh{client: cfClient}, nil
+}
+
+// Deprecated: use NewClient, which returns an error instead of panicking
 func New(opt *ClientOptions) Codefresh {
-	client := client.NewCfClient(opt.Host, opt.Token, opt.GraphqlPath, opt.Client)
-	return &codefresh{client: client}
+	cf, err := NewClient(opt.Host, opt.options()...)
+	if err != nil {
+		panic(err)
+	}
+
+	return cf
+}
+
+func (opt *ClientOptions) options() []client.Option {
+	return []client.Option{
+		client.WithToken(opt.Token),
+		client.WithAuthenticator(opt.Authenticator),
+		client.WithHTTPClient(opt.Client),
+		client.WithGraphqlPath(opt.GraphqlPath),
+		client.WithRetry(opt.Retry),
+		client.WithUserAgent(opt.UserAgent),
+		client.WithTimeout(opt.Timeout),
+		client.WithInsecure(opt.Insecure),
+	}
 }
 
 func (c *codefresh) AppProxy(ctx context.Context, runtime string, insecure bool) (ap.AppProxyAPI, error) {
@@ -56,7 +93,11 @@ func (c *codefresh) AppProxy(ctx context.Context, runtime string, insecure bool)
 		return nil, fmt.Errorf("failed to create app-proxy client for runtime %s: runtime does not have ingressHost configured", runtime)
 	}
 
-	apClient := c.client.AppProxyClient(host, insecure)
+	apClient, err := c.client.NewAppProxyClient(host, insecure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create app-proxy client for runtime %s: %w", runtime, err)
+	}
+
 	return ap.NewAppProxyClient(apClient), nil
 }
 
@@ -0,0 +1,132 @@
+package codefresh
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+)
+
+const (
+	// EnvAPIKey is an api key, when set the config file is not read
+	EnvAPIKey = "CF_API_KEY"
+	// EnvURL overrides the platform url (default: https://g.codefresh.io)
+	EnvURL = "CF_URL"
+	// EnvContext selects a context from the config file (default: the current context)
+	EnvContext = "CF_CONTEXT"
+	// EnvGraphqlPath overrides the graphql path
+	EnvGraphqlPath = "CF_GRAPHQL_PATH"
+
+	DefaultHost = "https://g.codefresh.io"
+
+	// on-prem installations commonly use self-signed certificates, and the codefresh cli honors this variable
+	envNodeTLSRejectUnauthorized = "NODE_TLS_REJECT_UNAUTHORIZED"
+)
+
+// NewFromConfig creates a client from a context in a .cfconfig file.
+// An empty path means $CFCONFIG or ~/.cfconfig, an empty contextName means the current context.
+// The token is read again from the file if it is rejected by the platform.
+// opts are applied after the options read from the context, a token or authenticator in opts replaces the context token.
+// The Beta flag of the context is ignored, beta accounts use the same endpoints.
+func NewFromConfig(path, contextName string, opts ...client.Option) (Codefresh, error) {
+	opt, err := contextClientOptions(path, contextName, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed creating client from config: %w", err)
+	}
+
+	opt.dropAuthIfSetBy(opts)
+
+	cf, err := NewClient(opt.Host, append(opt.options(), opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating client from context %q: %w", contextName, err)
+	}
+
+	return cf, nil
+}
+
+// NewFromEnv creates a client from environment variables, in the following order of precedence:
+//  1. CF_API_KEY, with CF_URL (or the default host)
+//  2. the CF_CONTEXT context (or the current context) from $CFCONFIG (or ~/.cfconfig), with its url overridden by CF_URL
+//
+// CF_GRAPHQL_PATH overrides the graphql path in both cases. opts are applied after the options read from the environment,
+// a token or authenticator in opts replaces the token from the environment.
+func NewFromEnv(opts ...client.Option) (Codefresh, error) {
+	var opt *ClientOptions
+	if token := os.Getenv(EnvAPIKey); token != "" {
+		host := os.Getenv(EnvURL)
+		if host == "" {
+			host = DefaultHost
+		}
+
+		opt = &ClientOptions{
+			Host:  host,
+			Token: token,
+		}
+	} else {
+		var err error
+		opt, err = contextClientOptions("", os.Getenv(EnvContext), os.Getenv(EnvURL))
+		if err != nil {
+			return nil, fmt.Errorf("failed creating client from env: %w", err)
+		}
+	}
+
+	if graphqlPath := os.Getenv(EnvGraphqlPath); graphqlPath != "" {
+		opt.GraphqlPath = graphqlPath
+	}
+
+	opt.dropAuthIfSetBy(opts)
+
+	cf, err := NewClient(opt.Host, append(opt.options(), opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating client from env: %w", err)
+	}
+
+	return cf, nil
+}
+
+// contextClientOptions maps a cfconfig context to client options, with an optional host override.
+// On-prem contexts skip TLS verification when NODE_TLS_REJECT_UNAUTHORIZED=0, like the codefresh cli.
+func contextClientOptions(path, contextName, host string) (*ClientOptions, error) {
+	config, err := utils.LoadCFConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+
+	cfContext, err := config.GetContext(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	if host == "" {
+		host = cfContext.URL
+	}
+
+	if host == "" {
+		host = DefaultHost
+	}
+
+	return &ClientOptions{
+		Host:          host,
+		Token:         cfContext.Token,
+		Authenticator: utils.NewContextAuthenticator(path, contextName),
+		Insecure:      cfContext.OnPrem && os.Getenv(envNodeTLSRejectUnauthorized) == "0",
+	}, nil
+}
+
+// dropAuthIfSetBy clears the token and authenticator when opts set their own, so the options of the caller win
+func (opt *ClientOptions) dropAuthIfSetBy(opts []client.Option) {
+	callerOpt := &client.ClientOptions{}
+	for _, o := range opts {
+		o(callerOpt)
+	}
+
+	if callerOpt.Token != "" || callerOpt.Authenticator != nil {
+		opt.Token = ""
+		opt.Authenticator = nil
+	}
+}
@@ -0,0 +1,215 @@
+package codefresh
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer returns a server that responds to /api/user with the Authorization header it received
+func newTestServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"userName": %q}`, r.Header.Get("Authorization"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeConfig(t *testing.T, serverURL string) string {
+	content := fmt.Sprintf(`contexts:
+  first:
+    name: first
+    url: %[1]s
+    token: first-token
+  second:
+    name: second
+    url: %[1]s
+    token: second-token
+  invalid:
+    name: invalid
+    url: ftp://some.host
+    token: invalid-token
+current-context: first
+`, serverURL)
+	path := filepath.Join(t.TempDir(), ".cfconfig")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func currentToken(t *testing.T, cf Codefresh) string {
+	user, err := cf.Rest().User().GetCurrent(context.Background())
+	assert.NoError(t, err)
+	return user.Name
+}
+
+func TestNewFromConfig(t *testing.T) {
+	server := newTestServer(t)
+	path := writeConfig(t, server.URL)
+	tests := []struct {
+		name        string
+		contextName string
+		opts        []client.Option
+		wantToken   string
+		wantErr     error
+		wantErrStr  string
+	}{
+		{
+			name:      "should use current context",
+			wantToken: "first-token",
+		},
+		{
+			name:      "should prefer a token passed by the caller",
+			opts:      []client.Option{client.WithToken("caller-token")},
+			wantToken: "caller-token",
+		},
+		{
+			name:      "should prefer an authenticator passed by the caller",
+			opts:      []client.Option{client.WithAuthenticator(client.NewStaticAuthenticator("caller-auth-token"))},
+			wantToken: "caller-auth-token",
+		},
+		{
+			name:        "should use named context",
+			contextName: "second",
+			wantToken:   "second-token",
+		},
+		{
+			name:        "should fail on missing context",
+			contextName: "missing",
+			wantErr:     utils.ErrContextNotFound,
+		},
+		{
+			name:        "should fail on invalid url",
+			contextName: "invalid",
+			wantErrStr:  "scheme must be http or https",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf, err := NewFromConfig(path, tt.contextName, tt.opts...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErrStr != "" {
+				assert.ErrorContains(t, err, tt.wantErrStr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantToken, currentToken(t, cf))
+		})
+	}
+}
+
+func TestNewFromEnv(t *testing.T) {
+	server := newTestServer(t)
+	path := writeConfig(t, server.URL)
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantToken  string
+		wantErr    error
+		wantErrStr string
+	}{
+		{
+			name: "should prefer CF_API_KEY over the config file",
+			env: map[string]string{
+				EnvAPIKey: "env-token",
+				EnvURL:    server.URL,
+			},
+			wantToken: "env-token",
+		},
+		{
+			name: "should use CF_CONTEXT from CFCONFIG",
+			env: map[string]string{
+				EnvContext: "second",
+			},
+			wantToken: "second-token",
+		},
+		{
+			name: "should override the context url with CF_URL",
+			env: map[string]string{
+				EnvContext: "invalid",
+				EnvURL:     server.URL,
+			},
+			wantToken: "invalid-token",
+		},
+		{
+			name: "should fail on invalid CF_URL",
+			env: map[string]string{
+				EnvAPIKey: "env-token",
+				EnvURL:    "some.host",
+			},
+			wantErrStr: "scheme must be http or https",
+		},
+		{
+			name: "should fail on missing context",
+			env: map[string]string{
+				EnvContext: "missing",
+			},
+			wantErr: utils.ErrContextNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(utils.CFConfigEnvVar, path)
+			for _, name := range []string{EnvAPIKey, EnvURL, EnvContext, EnvGraphqlPath} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			cf, err := NewFromEnv()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErrStr != "" {
+				assert.ErrorContains(t, err, tt.wantErrStr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantToken, currentToken(t, cf))
+		})
+	}
+}
+
+func TestNewFromConfig_RereadsRotatedToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "rotated-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fmt.Fprint(w, `{"userName": "ok"}`)
+	}))
+	defer server.Close()
+	path := writeConfig(t, server.URL)
+	cf, err := NewFromConfig(path, "")
+	assert.NoError(t, err)
+
+	_, err = cf.Rest().User().GetCurrent(context.Background())
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+
+	err = utils.UpdateCFConfig(path, func(config *utils.CFConfig) error {
+		config.Contexts["first"].Token = "rotated-token"
+		return nil
+	})
+	assert.NoError(t, err)
+
+	user, err := cf.Rest().User().GetCurrent(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", user.Name)
+}
@@ -10,7 +10,10 @@ import (
 
 type (
 	ClusterAPI interface {
-		List(ctx context.Context, runtime string) ([]platmodel.Cluster, error)
+		// List returns the clusters of all pages
+		List(ctx context.Context, runtime string, opts ...client.PageOption) ([]platmodel.Cluster, error)
+		// ListPages calls fn with the clusters of each page, until there are no more pages or fn returns an error
+		ListPages(ctx context.Context, runtime string, fn func(page []platmodel.Cluster) error, opts ...client.PageOption) error
 	}
 
 	cluster struct {
@@ -18,30 +21,21 @@ type (
 	}
 )
 
-func (c *cluster) List(ctx context.Context, runtime string) ([]platmodel.Cluster, error) {
-	after := ""
-	clusters := make([]platmodel.Cluster, 0)
-	for {
-		clusterSlice, err := c.getClusterSlice(ctx, runtime, after)
-		if err != nil {
-			return nil, err
-		}
+func (c *cluster) List(ctx context.Context, runtime string, opts ...client.PageOption) ([]platmodel.Cluster, error) {
+	return client.Paginate[platmodel.Cluster](ctx, c.fetcher(runtime), opts...)
+}
 
-		for i := range clusterSlice.Edges {
-			clusters = append(clusters, *clusterSlice.Edges[i].Node)
-		}
+func (c *cluster) ListPages(ctx context.Context, runtime string, fn func(page []platmodel.Cluster) error, opts ...client.PageOption) error {
+	return client.ForEachPage(ctx, c.fetcher(runtime), fn, opts...)
+}
 
-		if clusterSlice.PageInfo.HasNextPage {
-			after = *clusterSlice.PageInfo.EndCursor
-		} else {
-			break
-		}
+func (c *cluster) fetcher(runtime string) client.PageFetcher[*platmodel.ClusterSlice] {
+	return func(ctx context.Context, pagination client.PageArgs) (*platmodel.ClusterSlice, error) {
+		return c.getClusterSlice(ctx, runtime, pagination)
 	}
-
-	return clusters, nil
 }
 
-func (c *cluster) getClusterSlice(ctx context.Context, runtime string, after string) (*platmodel.ClusterSlice, error) {
+func (c *cluster) getClusterSlice(ctx context.Context, runtime string, pagination client.PageArgs) (*platmodel.ClusterSlice, error) {
 	query := `
 query clusters($runtime: String, $pagination: SlicePaginationArgs) {
 	clusters(runtime: $runtime, pagination: $pagination) {
@@ -72,10 +66,8 @@ query clusters($runtime: String, $pagination: SlicePaginationArgs) {
 	}
 }`
 	variables := map[string]any{
-		"runtime": runtime,
-		"pagination": map[string]any{
-			"after": after,
-		},
+		"runtime":    runtime,
+		"pagination": pagination,
 	}
 	res, err := client.GraphqlAPI[platmodel.ClusterSlice](ctx, c.client, query, variables)
 	if err != nil {
@@ -10,7 +10,10 @@ import (
 
 type (
 	ComponentAPI interface {
-		List(ctx context.Context, runtimeName string) ([]platmodel.Component, error)
+		// List returns the components of all pages
+		List(ctx context.Context, runtimeName string, opts ...client.PageOption) ([]platmodel.Component, error)
+		// ListPages calls fn with the components of each page, until there are no more pages or fn returns an error
+		ListPages(ctx context.Context, runtimeName string, fn func(page []platmodel.Component) error, opts ...client.PageOption) error
 	}
 
 	component struct {
@@ -18,10 +21,24 @@ type (
 	}
 )
 
-func (c *component) List(ctx context.Context, runtimeName string) ([]platmodel.Component, error) {
+func (c *component) List(ctx context.Context, runtimeName string, opts ...client.PageOption) ([]platmodel.Component, error) {
+	return client.Paginate[platmodel.Component](ctx, c.fetcher(runtimeName), opts...)
+}
+
+func (c *component) ListPages(ctx context.Context, runtimeName string, fn func(page []platmodel.Component) error, opts ...client.PageOption) error {
+	return client.ForEachPage(ctx, c.fetcher(runtimeName), fn, opts...)
+}
+
+func (c *component) fetcher(runtimeName string) client.PageFetcher[*platmodel.ComponentSlice] {
+	return func(ctx context.Context, pagination client.PageArgs) (*platmodel.ComponentSlice, error) {
+		return c.getComponentSlice(ctx, runtimeName, pagination)
+	}
+}
+
+func (c *component) getComponentSlice(ctx context.Context, runtimeName string, pagination client.PageArgs) (*platmodel.ComponentSlice, error) {
 	query := `
-query Components($runtime: String!) {
-	components(runtime: $runtime) {
+query Components($runtime: String!, $pagination: SlicePaginationArgs) {
+	components(runtime: $runtime, pagination: $pagination) {
 		edges {
 			node {
 				metadata {
@@ -45,20 +62,20 @@ query Components($runtime: String!) {
 				}
 			}
 		}
+		pageInfo {
+			endCursor
+			hasNextPage
+		}
 	}
 }`
 	variables := map[string]any{
-		"runtime": runtimeName,
+		"runtime":    runtimeName,
+		"pagination": pagination,
 	}
 	res, err := client.GraphqlAPI[platmodel.ComponentSlice](ctx, c.client, query, variables)
 	if err != nil {
 		return nil, fmt.Errorf("failed getting component list: %w", err)
 	}
 
-	components := make([]platmodel.Component, len(res.Edges))
-	for i := range res.Edges {
-		components[i] = *res.Edges[i].Node
-	}
-
-	return components, nil
+	return &res, nil
 }
@@ -2,13 +2,19 @@ package graphql
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/codefresh-io/go-sdk/pkg/client"
 	"github.com/codefresh-io/go-sdk/pkg/mocks"
 	platmodel "github.com/codefresh-io/go-sdk/pkg/model/platform"
 	"github.com/codefresh-io/go-sdk/pkg/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func Test_component_List(t *testing.T) {
@@ -18,7 +24,51 @@ func Test_component_List(t *testing.T) {
 		want        []platmodel.Component
 		wantErr     string
 		beforeFn    func(rt *mocks.MockRoundTripper)
-	}{}
+	}{
+		{
+			name:        "should follow the end cursor to the last page",
+			runtimeName: "some-runtime",
+			want: []platmodel.Component{
+				{Metadata: &platmodel.ObjectMeta{Name: "first"}},
+				{Metadata: &platmodel.ObjectMeta{Name: "second"}},
+			},
+			beforeFn: func(rt *mocks.MockRoundTripper) {
+				rt.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					body := struct {
+						Variables struct {
+							Runtime    string          `json:"runtime"`
+							Pagination client.PageArgs `json:"pagination"`
+						} `json:"variables"`
+					}{}
+					_ = json.NewDecoder(req.Body).Decode(&body)
+					assert.Equal(t, "some-runtime", body.Variables.Runtime)
+					data := `{"data": {"components": {"edges": [{"node": {"metadata": {"name": "first"}}}], "pageInfo": {"endCursor": "cursor-1", "hasNextPage": true}}}}`
+					if body.Variables.Pagination.After != nil {
+						assert.Equal(t, "cursor-1", *body.Variables.Pagination.After)
+						data = `{"data": {"components": {"edges": [{"node": {"metadata": {"name": "second"}}}], "pageInfo": {"endCursor": "cursor-2", "hasNextPage": false}}}}`
+					}
+
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader(data)),
+					}, nil
+				}).Times(2)
+			},
+		},
+		{
+			name:        "should fail when a page fails",
+			runtimeName: "some-runtime",
+			wantErr:     "failed getting component list: some error\n",
+			beforeFn: func(rt *mocks.MockRoundTripper) {
+				rt.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(_ *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader(`{"errors": [{"message": "some error"}]}`)),
+					}, nil
+				})
+			},
+		},
+	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cfClient, mockRT := utils.NewMockClient(t)
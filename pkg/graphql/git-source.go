@@ -10,7 +10,10 @@ import (
 
 type (
 	GitSourceAPI interface {
-		List(ctc context.Context, runtimeName string) ([]platmodel.GitSource, error)
+		// List returns the git-sources of all pages
+		List(ctx context.Context, runtimeName string, opts ...client.PageOption) ([]platmodel.GitSource, error)
+		// ListPages calls fn with the git-sources of each page, until there are no more pages or fn returns an error
+		ListPages(ctx context.Context, runtimeName string, fn func(page []platmodel.GitSource) error, opts ...client.PageOption) error
 	}
 
 	gitSource struct {
@@ -18,10 +21,24 @@ type (
 	}
 )
 
-func (c *gitSource) List(ctx context.Context, runtimeName string) ([]platmodel.GitSource, error) {
+func (c *gitSource) List(ctx context.Context, runtimeName string, opts ...client.PageOption) ([]platmodel.GitSource, error) {
+	return client.Paginate[platmodel.GitSource](ctx, c.fetcher(runtimeName), opts...)
+}
+
+func (c *gitSource) ListPages(ctx context.Context, runtimeName string, fn func(page []platmodel.GitSource) error, opts ...client.PageOption) error {
+	return client.ForEachPage(ctx, c.fetcher(runtimeName), fn, opts...)
+}
+
+func (c *gitSource) fetcher(runtimeName string) client.PageFetcher[*platmodel.GitSourceSlice] {
+	return func(ctx context.Context, pagination client.PageArgs) (*platmodel.GitSourceSlice, error) {
+		return c.getGitSourceSlice(ctx, runtimeName, pagination)
+	}
+}
+
+func (c *gitSource) getGitSourceSlice(ctx context.Context, runtimeName string, pagination client.PageArgs) (*platmodel.GitSourceSlice, error) {
 	query := `
-query GitSources($runtime: String) {
-	gitSources(runtime: $runtime) {
+query GitSources($runtime: String, $pagination: SlicePaginationArgs) {
+	gitSources(runtime: $runtime, pagination: $pagination) {
 		edges {
 			node {
 				metadata {
@@ -37,20 +54,20 @@ query GitSources($runtime: String) {
 				}
 			}
 		}
+		pageInfo {
+			endCursor
+			hasNextPage
+		}
 	}
 }`
 	variables := map[string]any{
-		"runtime": runtimeName,
+		"runtime":    runtimeName,
+		"pagination": pagination,
 	}
 	res, err := client.GraphqlAPI[platmodel.GitSourceSlice](ctx, c.client, query, variables)
 	if err != nil {
 		return nil, fmt.Errorf("failed getting git-source list: %w", err)
 	}
 
-	gitSources := make([]platmodel.GitSource, len(res.Edges))
-	for i := range res.Edges {
-		gitSources[i] = *res.Edges[i].Node
-	}
-
-	return gitSources, nil
+	return &res, nil
 }
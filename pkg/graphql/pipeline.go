@@ -11,7 +11,10 @@ import (
 type (
 	PipelineAPI interface {
 		Get(ctx context.Context, name, namespace, runtime string) (*platmodel.Pipeline, error)
-		List(ctx context.Context, filterArgs platmodel.PipelinesFilterArgs) ([]platmodel.Pipeline, error)
+		// List returns the pipelines of all pages
+		List(ctx context.Context, filterArgs platmodel.PipelinesFilterArgs, opts ...client.PageOption) ([]platmodel.Pipeline, error)
+		// ListPages calls fn with the pipelines of each page, until there are no more pages or fn returns an error
+		ListPages(ctx context.Context, filterArgs platmodel.PipelinesFilterArgs, fn func(page []platmodel.Pipeline) error, opts ...client.PageOption) error
 	}
 
 	pipeline struct {
@@ -56,10 +59,24 @@ query Pipeline(
 	return res, nil
 }
 
-func (c *pipeline) List(ctx context.Context, filterArgs platmodel.PipelinesFilterArgs) ([]platmodel.Pipeline, error) {
+func (c *pipeline) List(ctx context.Context, filterArgs platmodel.PipelinesFilterArgs, opts ...client.PageOption) ([]platmodel.Pipeline, error) {
+	return client.Paginate[platmodel.Pipeline](ctx, c.fetcher(filterArgs), opts...)
+}
+
+func (c *pipeline) ListPages(ctx context.Context, filterArgs platmodel.PipelinesFilterArgs, fn func(page []platmodel.Pipeline) error, opts ...client.PageOption) error {
+	return client.ForEachPage(ctx, c.fetcher(filterArgs), fn, opts...)
+}
+
+func (c *pipeline) fetcher(filterArgs platmodel.PipelinesFilterArgs) client.PageFetcher[*platmodel.PipelineSlice] {
+	return func(ctx context.Context, pagination client.PageArgs) (*platmodel.PipelineSlice, error) {
+		return c.getPipelineSlice(ctx, filterArgs, pagination)
+	}
+}
+
+func (c *pipeline) getPipelineSlice(ctx context.Context, filterArgs platmodel.PipelinesFilterArgs, pagination client.PageArgs) (*platmodel.PipelineSlice, error) {
 	query := `
-query Pipelines($filters: PipelinesFilterArgs) {
-	pipelines(filters: $filters) {
+query Pipelines($filters: PipelinesFilterArgs, $pagination: SlicePaginationArgs) {
+	pipelines(filters: $filters, pagination: $pagination) {
 		edges {
 			node {
 				metadata {
@@ -78,20 +95,20 @@ query Pipelines($filters: PipelinesFilterArgs) {
 				}
 			}
 		}
+		pageInfo {
+			endCursor
+			hasNextPage
+		}
 	}
 }`
 	variables := map[string]any{
-		"filters": filterArgs,
+		"filters":    filterArgs,
+		"pagination": pagination,
 	}
 	res, err := client.GraphqlAPI[platmodel.PipelineSlice](ctx, c.client, query, variables)
 	if err != nil {
 		return nil, fmt.Errorf("failed getting pipeline list: %w", err)
 	}
 
-	pipelines := make([]platmodel.Pipeline, len(res.Edges))
-	for i := range res.Edges {
-		pipelines[i] = *res.Edges[i].Node
-	}
-
-	return pipelines, nil
+	return &res, nil
 }
@@ -14,7 +14,10 @@ type (
 		Delete(ctx context.Context, runtimeName string) (int, error)
 		DeleteManaged(ctx context.Context, runtimeName string) (int, error)
 		Get(ctx context.Context, name string) (*platmodel.Runtime, error)
-		List(ctx context.Context) ([]platmodel.Runtime, error)
+		// List returns the runtimes of all pages
+		List(ctx context.Context, opts ...client.PageOption) ([]platmodel.Runtime, error)
+		// ListPages calls fn with the runtimes of each page, until there are no more pages or fn returns an error
+		ListPages(ctx context.Context, fn func(page []platmodel.Runtime) error, opts ...client.PageOption) error
 		MigrateRuntime(ctx context.Context, runtimeName string) error
 		ReportErrors(ctx context.Context, opts *platmodel.ReportRuntimeErrorsArgs) (int, error)
 		SetSharedConfigRepo(ctx context.Context, suggestedSharedConfigRepo string) (string, error)
@@ -119,16 +122,30 @@ query GetRuntime($name: String!) {
 	}
 
 	if res == nil {
-		return nil, fmt.Errorf("runtime '%s' does not exist", name)
+		return nil, fmt.Errorf("runtime '%s' does not exist: %w", name, client.ErrNotFound)
 	}
 
 	return res, nil
 }
 
-func (c *runtime) List(ctx context.Context) ([]platmodel.Runtime, error) {
+func (c *runtime) List(ctx context.Context, opts ...client.PageOption) ([]platmodel.Runtime, error) {
+	return client.Paginate[platmodel.Runtime](ctx, c.fetcher(), opts...)
+}
+
+func (c *runtime) ListPages(ctx context.Context, fn func(page []platmodel.Runtime) error, opts ...client.PageOption) error {
+	return client.ForEachPage(ctx, c.fetcher(), fn, opts...)
+}
+
+func (c *runtime) fetcher() client.PageFetcher[*platmodel.RuntimeSlice] {
+	return func(ctx context.Context, pagination client.PageArgs) (*platmodel.RuntimeSlice, error) {
+		return c.getRuntimeSlice(ctx, pagination)
+	}
+}
+
+func (c *runtime) getRuntimeSlice(ctx context.Context, pagination client.PageArgs) (*platmodel.RuntimeSlice, error) {
 	query := `
-query Runtimes {
-	runtimes {
+query Runtimes($pagination: SlicePaginationArgs) {
+	runtimes(pagination: $pagination) {
 		edges {
 			node {
 				metadata {
@@ -151,20 +168,21 @@ query Runtimes {
 				installationType
 			}
 		}
+		pageInfo {
+			endCursor
+			hasNextPage
+		}
 	}
 }`
-	variables := map[string]any{}
+	variables := map[string]any{
+		"pagination": pagination,
+	}
 	res, err := client.GraphqlAPI[platmodel.RuntimeSlice](ctx, c.client, query, variables)
 	if err != nil {
 		return nil, fmt.Errorf("failed getting runtime list: %w", err)
 	}
 
-	runtimes := make([]platmodel.Runtime, len(res.Edges))
-	for i := range res.Edges {
-		runtimes[i] = *res.Edges[i].Node
-	}
-
-	return runtimes, nil
+	return &res, nil
 }
 
 func (c *runtime) MigrateRuntime(ctx context.Context, runtimeName string) error {
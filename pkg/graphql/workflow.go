@@ -11,7 +11,10 @@ import (
 type (
 	WorkflowAPI interface {
 		Get(ctx context.Context, uid string) (*platmodel.Workflow, error)
-		List(ctx context.Context, filterArgs platmodel.WorkflowsFilterArgs) ([]platmodel.Workflow, error)
+		// List returns the workflows of all pages
+		List(ctx context.Context, filterArgs platmodel.WorkflowsFilterArgs, opts ...client.PageOption) ([]platmodel.Workflow, error)
+		// ListPages calls fn with the workflows of each page, until there are no more pages or fn returns an error
+		ListPages(ctx context.Context, filterArgs platmodel.WorkflowsFilterArgs, fn func(page []platmodel.Workflow) error, opts ...client.PageOption) error
 	}
 
 	workflow struct {
@@ -67,10 +70,24 @@ query Workflow($uid: String!) {
 	return res, nil
 }
 
-func (c *workflow) List(ctx context.Context, filterArgs platmodel.WorkflowsFilterArgs) ([]platmodel.Workflow, error) {
+func (c *workflow) List(ctx context.Context, filterArgs platmodel.WorkflowsFilterArgs, opts ...client.PageOption) ([]platmodel.Workflow, error) {
+	return client.Paginate[platmodel.Workflow](ctx, c.fetcher(filterArgs), opts...)
+}
+
+func (c *workflow) ListPages(ctx context.Context, filterArgs platmodel.WorkflowsFilterArgs, fn func(page []platmodel.Workflow) error, opts ...client.PageOption) error {
+	return client.ForEachPage(ctx, c.fetcher(filterArgs), fn, opts...)
+}
+
+func (c *workflow) fetcher(filterArgs platmodel.WorkflowsFilterArgs) client.PageFetcher[*platmodel.WorkflowSlice] {
+	return func(ctx context.Context, pagination client.PageArgs) (*platmodel.WorkflowSlice, error) {
+		return c.getWorkflowSlice(ctx, filterArgs, pagination)
+	}
+}
+
+func (c *workflow) getWorkflowSlice(ctx context.Context, filterArgs platmodel.WorkflowsFilterArgs, pagination client.PageArgs) (*platmodel.WorkflowSlice, error) {
 	query := `
-query Workflows($filters: WorkflowsFilterArgs) {
-	workflows(filters: $filters) {
+query Workflows($filters: WorkflowsFilterArgs, $pagination: SlicePaginationArgs) {
+	workflows(filters: $filters, pagination: $pagination) {
 		edges {
 			node {
 				metadata {
@@ -106,20 +123,20 @@ query Workflows($filters: WorkflowsFilterArgs) {
 					}
 			}
 		}
+		pageInfo {
+			endCursor
+			hasNextPage
+		}
 	}
 }`
 	variables := map[string]any{
-		"filters": filterArgs,
+		"filters":    filterArgs,
+		"pagination": pagination,
 	}
 	res, err := client.GraphqlAPI[platmodel.WorkflowSlice](ctx, c.client, query, variables)
 	if err != nil {
 		return nil, fmt.Errorf("failed getting workflow list: %w", err)
 	}
 
-	workflows := make([]platmodel.Workflow, len(res.Edges))
-	for i := range res.Edges {
-		workflows[i] = *res.Edges[i].Node
-	}
-
-	return workflows, nil
+	return &res, nil
 }
@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net/url"
 
 	"github.com/codefresh-io/go-sdk/pkg/client"
 )
 
 type (
+	// Deprecated: use ArgoV2API instead
 	ArgoAPI interface {
 		CreateIntegration(integration IntegrationPayloadData) error
 		DeleteIntegrationByName(name string) error
@@ -19,10 +22,25 @@ type (
 		UpdateIntegration(name string, integration IntegrationPayloadData) error
 	}
 
+	ArgoV2API interface {
+		CreateIntegration(ctx context.Context, integration IntegrationPayloadData) error
+		DeleteIntegrationByName(ctx context.Context, name string) error
+		GetIntegrationByName(ctx context.Context, name string) (*IntegrationPayload, error)
+		GetIntegrations(ctx context.Context) ([]IntegrationPayload, error)
+		HeartBeat(ctx context.Context, error string, version string, integration string) error
+		SendResources(ctx context.Context, kind string, items any, amount int, integration string) error
+		UpdateIntegration(ctx context.Context, name string, integration IntegrationPayloadData) error
+	}
+
 	argo struct {
 		client *client.CfClient
 	}
 
+	// argoShim implements the deprecated ArgoAPI
+	argoShim struct {
+		v2 *argo
+	}
+
 	IntegrationItem struct {
 		Amount int `json:"amount"`
 	}
@@ -56,8 +74,8 @@ type (
 	}
 )
 
-func (a *argo) CreateIntegration(integration IntegrationPayloadData) error {
-	_, err := a.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (a *argo) CreateIntegration(ctx context.Context, integration IntegrationPayloadData) error {
+	_, err := a.client.RestAPI(ctx, &client.RequestOptions{
 		Path:   "/api/argo",
 		Method: "POST",
 		Body: &IntegrationPayload{
@@ -72,10 +90,10 @@ func (a *argo) CreateIntegration(integration IntegrationPayloadData) error {
 	return nil
 }
 
-func (a *argo) DeleteIntegrationByName(name string) error {
-	_, err := a.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (a *argo) DeleteIntegrationByName(ctx context.Context, name string) error {
+	_, err := a.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "DELETE",
-		Path:   fmt.Sprintf("/api/argo/%s", name),
+		Path:   fmt.Sprintf("/api/argo/%s", url.PathEscape(name)),
 	})
 	if err != nil {
 		return fmt.Errorf("failed deleting an argo integration: %w", err)
@@ -84,10 +102,10 @@ func (a *argo) DeleteIntegrationByName(name string) error {
 	return nil
 }
 
-func (a *argo) GetIntegrationByName(name string) (*IntegrationPayload, error) {
-	res, err := a.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (a *argo) GetIntegrationByName(ctx context.Context, name string) (*IntegrationPayload, error) {
+	res, err := a.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "GET",
-		Path:   fmt.Sprintf("/api/argo/%s", name),
+		Path:   fmt.Sprintf("/api/argo/%s", url.PathEscape(name)),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed getting an argo integration: %w", err)
@@ -97,8 +115,8 @@ func (a *argo) GetIntegrationByName(name string) (*IntegrationPayload, error) {
 	return result, json.Unmarshal(res, result)
 }
 
-func (a *argo) GetIntegrations() ([]IntegrationPayload, error) {
-	res, err := a.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (a *argo) GetIntegrations(ctx context.Context) ([]IntegrationPayload, error) {
+	res, err := a.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "GET",
 		Path:   "/api/argo",
 	})
@@ -110,7 +128,7 @@ func (a *argo) GetIntegrations() ([]IntegrationPayload, error) {
 	return result, json.Unmarshal(res, &result)
 }
 
-func (a *argo) HeartBeat(error string, version string, integration string) error {
+func (a *argo) HeartBeat(ctx context.Context, error string, version string, integration string) error {
 	var body = Heartbeat{}
 	if error != "" {
 		body.Error = error
@@ -120,10 +138,12 @@ func (a *argo) HeartBeat(error string, version string, integration string) error
 		body.AgentVersion = version
 	}
 
-	_, err := a.client.RestAPI(context.TODO(), &client.RequestOptions{
+	_, err := a.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "POST",
-		Path:   fmt.Sprintf("/api/argo-agent/%s/heartbeat", integration),
+		Path:   fmt.Sprintf("/api/argo-agent/%s/heartbeat", url.PathEscape(integration)),
 		Body:   body,
+		// a heartbeat can safely be sent more than once
+		Idempotent: true,
 	})
 	if err != nil {
 		return fmt.Errorf("failed sending argo heartbeat: %w", err)
@@ -132,15 +152,17 @@ func (a *argo) HeartBeat(error string, version string, integration string) error
 	return nil
 }
 
-func (a *argo) SendResources(kind string, items any, amount int, integration string) error {
+func (a *argo) SendResources(ctx context.Context, kind string, items any, amount int, integration string) error {
 	if items == nil {
 		return nil
 	}
 
-	_, err := a.client.RestAPI(context.TODO(), &client.RequestOptions{
+	_, err := a.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "POST",
-		Path:   fmt.Sprintf("/api/argo-agent/%s", integration),
+		Path:   fmt.Sprintf("/api/argo-agent/%s", url.PathEscape(integration)),
 		Body:   &AgentState{Kind: kind, Items: items},
+		// the full state is sent on every call
+		Idempotent: true,
 	})
 	if err != nil {
 		return fmt.Errorf("failed sending argo resources: %w", err)
@@ -149,10 +171,10 @@ func (a *argo) SendResources(kind string, items any, amount int, integration str
 	return nil
 }
 
-func (a *argo) UpdateIntegration(name string, integration IntegrationPayloadData) error {
-	_, err := a.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (a *argo) UpdateIntegration(ctx context.Context, name string, integration IntegrationPayloadData) error {
+	_, err := a.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "PUT",
-		Path:   fmt.Sprintf("/api/argo/%s", name),
+		Path:   fmt.Sprintf("/api/argo/%s", url.PathEscape(name)),
 		Body: &IntegrationPayload{
 			Type: "argo-cd",
 			Data: integration,
@@ -164,3 +186,53 @@ func (a *argo) UpdateIntegration(name string, integration IntegrationPayloadData
 
 	return nil
 }
+
+func (a *argoShim) CreateIntegration(integration IntegrationPayloadData) error {
+	return a.v2.CreateIntegration(context.Background(), integration)
+}
+
+func (a *argoShim) DeleteIntegrationByName(name string) error {
+	return a.v2.DeleteIntegrationByName(context.Background(), name)
+}
+
+func (a *argoShim) GetIntegrationByName(name string) (*IntegrationPayload, error) {
+	return a.v2.GetIntegrationByName(context.Background(), name)
+}
+
+func (a *argoShim) GetIntegrations() ([]IntegrationPayload, error) {
+	return a.v2.GetIntegrations(context.Background())
+}
+
+func (a *argoShim) HeartBeat(error string, version string, integration string) error {
+	return a.v2.HeartBeat(context.Background(), error, version, integration)
+}
+
+func (a *argoShim) SendResources(kind string, items any, amount int, integration string) error {
+	return a.v2.SendResources(context.Background(), kind, items, amount, integration)
+}
+
+func (a *argoShim) UpdateIntegration(name string, integration IntegrationPayloadData) error {
+	return a.v2.UpdateIntegration(context.Background(), name, integration)
+}
+
+// LogValue redacts the password and token of the integration
+func (i IntegrationPayloadData) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("name", i.Name),
+		slog.String("url", i.Url),
+		slog.String("username", stringValue(i.Username)),
+		slog.String("password", client.Redact(stringValue(i.Password))),
+		slog.String("token", client.Redact(stringValue(i.Token))),
+		slog.String("clusterName", stringValue(i.ClusterName)),
+		slog.String("serverVersion", stringValue(i.ServerVersion)),
+		slog.String("provider", stringValue(i.Provider)),
+	)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
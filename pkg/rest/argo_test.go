@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -26,7 +27,7 @@ func Test_argo_GetIntegrations(t *testing.T) {
 			a := &argo{
 				client: cfClient,
 			}
-			got, err := a.GetIntegrations()
+			got, err := a.GetIntegrations(context.Background())
 			if err != nil || tt.wantErr != "" {
 				assert.EqualError(t, err, tt.wantErr)
 				return
@@ -57,7 +58,7 @@ func Test_argo_GetIntegrationByName(t *testing.T) {
 			a := &argo{
 				client: cfClient,
 			}
-			got, err := a.GetIntegrationByName(tt.integrationName)
+			got, err := a.GetIntegrationByName(context.Background(), tt.integrationName)
 			if err != nil || tt.wantErr != "" {
 				assert.EqualError(t, err, tt.wantErr)
 				return
@@ -87,7 +88,7 @@ func Test_argo_DeleteIntegrationByName(t *testing.T) {
 			a := &argo{
 				client: cfClient,
 			}
-			if err := a.DeleteIntegrationByName(tt.integrationName); err != nil || tt.wantErr != "" {
+			if err := a.DeleteIntegrationByName(context.Background(), tt.integrationName); err != nil || tt.wantErr != "" {
 				assert.EqualError(t, err, tt.wantErr)
 			}
 		})
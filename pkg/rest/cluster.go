@@ -4,20 +4,33 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net/url"
 
 	"github.com/codefresh-io/go-sdk/pkg/client"
 )
 
 type (
+	// Deprecated: use ClusterV2API instead
 	ClusterAPI interface {
 		GetAccountClusters() ([]ClusterMinified, error)
 		GetClusterCredentialsByAccountId(selector string) (*Cluster, error)
 	}
 
+	ClusterV2API interface {
+		GetAccountClusters(ctx context.Context) ([]ClusterMinified, error)
+		GetClusterCredentialsByAccountId(ctx context.Context, selector string) (*Cluster, error)
+	}
+
 	cluster struct {
 		client *client.CfClient
 	}
 
+	// clusterShim implements the deprecated ClusterAPI
+	clusterShim struct {
+		v2 *cluster
+	}
+
 	Cluster struct {
 		Auth struct {
 			Bearer string
@@ -36,8 +49,8 @@ type (
 	}
 )
 
-func (p *cluster) GetAccountClusters() ([]ClusterMinified, error) {
-	res, err := p.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (p *cluster) GetAccountClusters(ctx context.Context) ([]ClusterMinified, error) {
+	res, err := p.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "GET",
 		Path:   "/api/clusters",
 	})
@@ -49,10 +62,10 @@ func (p *cluster) GetAccountClusters() ([]ClusterMinified, error) {
 	return result, json.Unmarshal(res, &result)
 }
 
-func (p *cluster) GetClusterCredentialsByAccountId(selector string) (*Cluster, error) {
-	res, err := p.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (p *cluster) GetClusterCredentialsByAccountId(ctx context.Context, selector string) (*Cluster, error) {
+	res, err := p.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "GET",
-		Path:   fmt.Sprintf("/api/clusters/%s/credentials", selector),
+		Path:   fmt.Sprintf("/api/clusters/%s/credentials", url.PathEscape(selector)),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed getting account cluster credentials: %w", err)
@@ -61,3 +74,22 @@ func (p *cluster) GetClusterCredentialsByAccountId(selector string) (*Cluster, e
 	result := &Cluster{}
 	return result, json.Unmarshal(res, result)
 }
+
+func (p *clusterShim) GetAccountClusters() ([]ClusterMinified, error) {
+	return p.v2.GetAccountClusters(context.Background())
+}
+
+func (p *clusterShim) GetClusterCredentialsByAccountId(selector string) (*Cluster, error) {
+	return p.v2.GetClusterCredentialsByAccountId(context.Background(), selector)
+}
+
+// LogValue redacts the bearer token of the cluster
+func (c Cluster) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("url", c.Url),
+		slog.String("ca", c.Ca),
+		slog.Group("auth",
+			slog.String("bearer", client.Redact(c.Auth.Bearer)),
+		),
+	)
+}
@@ -1,12 +1,17 @@
 package rest
 
 import (
+	"context"
+	"io"
+	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/codefresh-io/go-sdk/pkg/mocks"
 	"github.com/codefresh-io/go-sdk/pkg/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func Test_cluster_GetAccountClusters(t *testing.T) {
@@ -26,7 +31,7 @@ func Test_cluster_GetAccountClusters(t *testing.T) {
 			p := &cluster{
 				client: cfClient,
 			}
-			got, err := p.GetAccountClusters()
+			got, err := p.GetAccountClusters(context.Background())
 			if err != nil || tt.wantErr != "" {
 				assert.EqualError(t, err, tt.wantErr)
 				return
@@ -46,7 +51,22 @@ func Test_cluster_GetClusterCredentialsByAccountId(t *testing.T) {
 		want     *Cluster
 		wantErr  string
 		beforeFn func(rt *mocks.MockRoundTripper)
-	}{}
+	}{
+		{
+			name:     "should escape the selector",
+			selector: "some/selector",
+			want:     &Cluster{Url: "https://some.cluster"},
+			beforeFn: func(rt *mocks.MockRoundTripper) {
+				rt.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, "/api/clusters/some%2Fselector/credentials", req.URL.EscapedPath())
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader(`{"url": "https://some.cluster"}`)),
+					}, nil
+				})
+			},
+		},
+	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cfClient, mockRT := utils.NewMockClient(t)
@@ -57,7 +77,7 @@ func Test_cluster_GetClusterCredentialsByAccountId(t *testing.T) {
 			p := &cluster{
 				client: cfClient,
 			}
-			got, err := p.GetClusterCredentialsByAccountId(tt.selector)
+			got, err := p.GetClusterCredentialsByAccountId(context.Background(), tt.selector)
 			if err != nil || tt.wantErr != "" {
 				assert.EqualError(t, err, tt.wantErr)
 				return
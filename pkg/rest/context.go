@@ -4,21 +4,55 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net/url"
 
 	"github.com/codefresh-io/go-sdk/pkg/client"
 )
 
 type (
+	// Deprecated: use ContextV2API instead
 	ContextAPI interface {
 		GetDefaultGitContext() (*ContextPayload, error)
 		GetGitContextByName(name string) (*ContextPayload, error)
 		GetGitContexts() ([]ContextPayload, error)
 	}
 
+	ContextV2API interface {
+		Create(ctx context.Context, sharedContext *SharedContext) (*SharedContext, error)
+		// CreateGitContext creates a git context, its type is set from the type of the auth when empty
+		CreateGitContext(ctx context.Context, gitContext *GitContext) (*GitContext, error)
+		// Delete deletes a context of any type, including git contexts
+		Delete(ctx context.Context, name string) error
+		// Get returns a context of any type, its secret values are masked unless opt.Decrypt is set
+		Get(ctx context.Context, name string, opt *ContextGetOptions) (*SharedContext, error)
+		GetDefaultGitContext(ctx context.Context) (*ContextPayload, error)
+		// GetGitContext returns a git context with its typed auth, its secret values are masked unless opt.Decrypt is set
+		GetGitContext(ctx context.Context, name string, opt *ContextGetOptions) (*GitContext, error)
+		// GetGitContextByName returns the git context with its secret values, use GetGitContext for its typed auth
+		GetGitContextByName(ctx context.Context, name string) (*ContextPayload, error)
+		// GetGitContexts returns the github and gitlab contexts with their secret values, use ListGitContexts for
+		// the other git types and their typed auth
+		GetGitContexts(ctx context.Context) ([]ContextPayload, error)
+		// List returns the contexts of the requested types, their secret values are masked unless opt.Decrypt is set
+		List(ctx context.Context, opt *ContextListOptions) ([]SharedContext, error)
+		// ListGitContexts returns the git contexts of the requested types (default: all git types)
+		ListGitContexts(ctx context.Context, opt *ContextListOptions) ([]GitContext, error)
+		SetDefaultGitContext(ctx context.Context, name string) error
+		// Update replaces the data of the context with the same name
+		Update(ctx context.Context, sharedContext *SharedContext) (*SharedContext, error)
+		UpdateGitContext(ctx context.Context, gitContext *GitContext) (*GitContext, error)
+	}
+
 	v1Context struct {
 		client *client.CfClient
 	}
 
+	// contextShim implements the deprecated ContextAPI
+	contextShim struct {
+		v2 v1Context
+	}
+
 	ContextPayload struct {
 		Metadata struct {
 			Name string `json:"name"`
@@ -38,6 +72,12 @@ type (
 					AppId          string `json:"appId"`
 					InstallationId string `json:"installationId"`
 					PrivateKey     string `json:"privateKey"`
+					// for azure devops
+					Organization string `json:"organization"`
+					// for codecommit
+					Region          string `json:"region"`
+					AccessKeyID     string `json:"accessKeyId"`
+					SecretAccessKey string `json:"secretAccessKey"`
 				} `json:"auth"`
 			} `json:"data"`
 		} `json:"spec"`
@@ -49,8 +89,8 @@ type (
 	}
 )
 
-func (c v1Context) GetDefaultGitContext() (*ContextPayload, error) {
-	res, err := c.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (c v1Context) GetDefaultGitContext(ctx context.Context) (*ContextPayload, error) {
+	res, err := c.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "GET",
 		Path:   "/api/contexts/git/default",
 	})
@@ -62,10 +102,10 @@ func (c v1Context) GetDefaultGitContext() (*ContextPayload, error) {
 	return result, json.Unmarshal(res, result)
 }
 
-func (c v1Context) GetGitContextByName(name string) (*ContextPayload, error) {
-	res, err := c.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (c v1Context) GetGitContextByName(ctx context.Context, name string) (*ContextPayload, error) {
+	res, err := c.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "GET",
-		Path:   "/api/contexts/" + name,
+		Path:   "/api/contexts/" + url.PathEscape(name),
 		Query: map[string]any{
 			"decrypt": "true",
 		},
@@ -78,8 +118,8 @@ func (c v1Context) GetGitContextByName(name string) (*ContextPayload, error) {
 	return result, json.Unmarshal(res, result)
 }
 
-func (c v1Context) GetGitContexts() ([]ContextPayload, error) {
-	res, err := c.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (c v1Context) GetGitContexts(ctx context.Context) ([]ContextPayload, error) {
+	res, err := c.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "GET",
 		Path:   "/api/contexts",
 		Query: map[string]any{
@@ -94,3 +134,40 @@ func (c v1Context) GetGitContexts() ([]ContextPayload, error) {
 	result := make([]ContextPayload, 0)
 	return result, json.Unmarshal(res, &result)
 }
+
+func (c *contextShim) GetDefaultGitContext() (*ContextPayload, error) {
+	return c.v2.GetDefaultGitContext(context.Background())
+}
+
+func (c *contextShim) GetGitContextByName(name string) (*ContextPayload, error) {
+	return c.v2.GetGitContextByName(context.Background(), name)
+}
+
+func (c *contextShim) GetGitContexts() ([]ContextPayload, error) {
+	return c.v2.GetGitContexts(context.Background())
+}
+
+// LogValue redacts the credentials of the context
+func (c ContextPayload) LogValue() slog.Value {
+	auth := c.Spec.Data.Auth
+	return slog.GroupValue(
+		slog.String("name", c.Metadata.Name),
+		slog.String("type", c.Spec.Type),
+		slog.Group("auth",
+			slog.String("type", auth.Type),
+			slog.String("username", auth.Username),
+			slog.String("password", client.Redact(auth.Password)),
+			slog.String("apiHost", auth.ApiHost),
+			slog.String("apiURL", auth.ApiURL),
+			slog.String("apiPathPrefix", auth.ApiPathPrefix),
+			slog.String("sshPrivateKey", client.Redact(auth.SshPrivateKey)),
+			slog.String("appId", auth.AppId),
+			slog.String("installationId", auth.InstallationId),
+			slog.String("privateKey", client.Redact(auth.PrivateKey)),
+			slog.String("organization", auth.Organization),
+			slog.String("region", auth.Region),
+			slog.String("accessKeyId", auth.AccessKeyID),
+			slog.String("secretAccessKey", client.Redact(auth.SecretAccessKey)),
+		),
+	)
+}
@@ -1,14 +1,25 @@
 package rest
 
 import (
+	"context"
+	"io"
+	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/codefresh-io/go-sdk/pkg/mocks"
 	"github.com/codefresh-io/go-sdk/pkg/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+func contextPayload(name string) *ContextPayload {
+	payload := &ContextPayload{}
+	payload.Metadata.Name = name
+	return payload
+}
+
 func Test_v1Context_GetDefaultGitContext(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -26,7 +37,7 @@ func Test_v1Context_GetDefaultGitContext(t *testing.T) {
 			c := v1Context{
 				client: cfClient,
 			}
-			got, err := c.GetDefaultGitContext()
+			got, err := c.GetDefaultGitContext(context.Background())
 			if err != nil || tt.wantErr != "" {
 				assert.EqualError(t, err, tt.wantErr)
 				return
@@ -46,7 +57,23 @@ func Test_v1Context_GetGitContextByName(t *testing.T) {
 		want        *ContextPayload
 		wantErr     string
 		beforeFn    func(rt *mocks.MockRoundTripper)
-	}{}
+	}{
+		{
+			name:        "should escape the name",
+			contextName: "some/context",
+			want:        contextPayload("some/context"),
+			beforeFn: func(rt *mocks.MockRoundTripper) {
+				rt.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, "/api/contexts/some%2Fcontext", req.URL.EscapedPath())
+					assert.Equal(t, "true", req.URL.Query().Get("decrypt"))
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader(`{"metadata": {"name": "some/context"}}`)),
+					}, nil
+				})
+			},
+		},
+	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cfClient, mockRT := utils.NewMockClient(t)
@@ -57,7 +84,7 @@ func Test_v1Context_GetGitContextByName(t *testing.T) {
 			c := v1Context{
 				client: cfClient,
 			}
-			got, err := c.GetGitContextByName(tt.contextName)
+			got, err := c.GetGitContextByName(context.Background(), tt.contextName)
 			if err != nil || tt.wantErr != "" {
 				assert.EqualError(t, err, tt.wantErr)
 				return
@@ -76,7 +103,22 @@ func Test_v1Context_GetGitContexts(t *testing.T) {
 		want     []ContextPayload
 		wantErr  string
 		beforeFn func(rt *mocks.MockRoundTripper)
-	}{}
+	}{
+		{
+			name: "should get the github, gitlab and github app contexts",
+			want: []ContextPayload{*contextPayload("github")},
+			beforeFn: func(rt *mocks.MockRoundTripper) {
+				rt.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, "/api/contexts", req.URL.Path)
+					assert.Equal(t, []string{"git.github", "git.gitlab", "git.github-app"}, req.URL.Query()["type"])
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader(`[{"metadata": {"name": "github"}}]`)),
+					}, nil
+				})
+			},
+		},
+	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cfClient, mockRT := utils.NewMockClient(t)
@@ -87,7 +129,7 @@ func Test_v1Context_GetGitContexts(t *testing.T) {
 			c := v1Context{
 				client: cfClient,
 			}
-			got, err := c.GetGitContexts()
+			got, err := c.GetGitContexts(context.Background())
 			if err != nil || tt.wantErr != "" {
 				assert.EqualError(t, err, tt.wantErr)
 				return
@@ -0,0 +1,422 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+)
+
+type (
+	// GitContext is a git provider context. The type of Spec.Data.Auth depends on Spec.Type:
+	//   - git.github: *GitHubAuth
+	//   - git.github-app: *GitHubAppAuth
+	//   - git.gitlab: *GitLabAuth
+	//   - git.bitbucket: *BitbucketAuth
+	//   - git.bitbucket-server: *BitbucketServerAuth
+	//   - git.azure: *AzureDevOpsAuth
+	//   - git.gerrit: *GerritAuth
+	//   - git.codecommit: *CodeCommitAuth
+	GitContext struct {
+		Metadata ContextMetadata `json:"metadata"`
+		Spec     GitContextSpec  `json:"spec"`
+	}
+
+	GitContextSpec struct {
+		// Type is set from the type of Data.Auth when empty
+		Type ContextType    `json:"type"`
+		Data GitContextData `json:"data"`
+	}
+
+	GitContextData struct {
+		Auth GitAuth `json:"auth"`
+		// SharingPolicy is who can use the context in pipelines, e.g. AccountAdmins or AllUsersInAccount
+		SharingPolicy  string `json:"sharingPolicy,omitempty"`
+		BehindFirewall bool   `json:"behindFirewall,omitempty"`
+	}
+
+	// GitAuth is the provider specific auth of a git context
+	GitAuth interface {
+		ContextType() ContextType
+	}
+
+	GitHubAuth struct {
+		Type          string `json:"type"`
+		Username      string `json:"username,omitempty"`
+		Password      string `json:"password"`
+		ApiHost       string `json:"apiHost,omitempty"`
+		ApiPathPrefix string `json:"apiPathPrefix,omitempty"`
+		SshPrivateKey string `json:"sshPrivateKey,omitempty"`
+	}
+
+	GitHubAppAuth struct {
+		Type           string `json:"type"`
+		AppId          string `json:"appId"`
+		InstallationId string `json:"installationId"`
+		PrivateKey     string `json:"privateKey"`
+		ApiHost        string `json:"apiHost,omitempty"`
+		ApiPathPrefix  string `json:"apiPathPrefix,omitempty"`
+		SshPrivateKey  string `json:"sshPrivateKey,omitempty"`
+	}
+
+	GitLabAuth struct {
+		Type          string `json:"type"`
+		Username      string `json:"username,omitempty"`
+		Password      string `json:"password"`
+		ApiURL        string `json:"apiURL,omitempty"`
+		SshPrivateKey string `json:"sshPrivateKey,omitempty"`
+	}
+
+	// BitbucketAuth is the auth of bitbucket cloud, Password is an app password of the user
+	BitbucketAuth struct {
+		Type          string `json:"type"`
+		Username      string `json:"username"`
+		Password      string `json:"password"`
+		SshPrivateKey string `json:"sshPrivateKey,omitempty"`
+	}
+
+	BitbucketServerAuth struct {
+		Type          string `json:"type"`
+		Username      string `json:"username"`
+		Password      string `json:"password"`
+		ApiHost       string `json:"apiHost"`
+		ApiPathPrefix string `json:"apiPathPrefix,omitempty"`
+		SshPrivateKey string `json:"sshPrivateKey,omitempty"`
+	}
+
+	// AzureDevOpsAuth is the auth of azure devops, Password is a personal access token
+	AzureDevOpsAuth struct {
+		Type          string `json:"type"`
+		Organization  string `json:"organization"`
+		Password      string `json:"password"`
+		ApiHost       string `json:"apiHost,omitempty"`
+		SshPrivateKey string `json:"sshPrivateKey,omitempty"`
+	}
+
+	GerritAuth struct {
+		Type          string `json:"type"`
+		Username      string `json:"username"`
+		Password      string `json:"password"`
+		ApiHost       string `json:"apiHost"`
+		SshPrivateKey string `json:"sshPrivateKey,omitempty"`
+	}
+
+	CodeCommitAuth struct {
+		Type            string `json:"type"`
+		Region          string `json:"region"`
+		AccessKeyID     string `json:"accessKeyId"`
+		SecretAccessKey string `json:"secretAccessKey"`
+	}
+)
+
+const (
+	ContextTypeGitHub          ContextType = "git.github"
+	ContextTypeGitHubApp       ContextType = "git.github-app"
+	ContextTypeGitLab          ContextType = "git.gitlab"
+	ContextTypeBitbucket       ContextType = "git.bitbucket"
+	ContextTypeBitbucketServer ContextType = "git.bitbucket-server"
+	ContextTypeAzureDevOps     ContextType = "git.azure"
+	ContextTypeGerrit          ContextType = "git.gerrit"
+	ContextTypeCodeCommit      ContextType = "git.codecommit"
+
+	defaultGitAuthType = "basic"
+)
+
+// gitContextTypes are the types of all git contexts
+var gitContextTypes = []ContextType{
+	ContextTypeGitHub,
+	ContextTypeGitHubApp,
+	ContextTypeGitLab,
+	ContextTypeBitbucket,
+	ContextTypeBitbucketServer,
+	ContextTypeAzureDevOps,
+	ContextTypeGerrit,
+	ContextTypeCodeCommit,
+}
+
+// secretGitAuthFields are the auth fields that hold credentials, in all providers
+var secretGitAuthFields = []string{"password", "sshPrivateKey", "privateKey", "secretAccessKey"}
+
+func (*GitHubAuth) ContextType() ContextType          { return ContextTypeGitHub }
+func (*GitHubAppAuth) ContextType() ContextType       { return ContextTypeGitHubApp }
+func (*GitLabAuth) ContextType() ContextType          { return ContextTypeGitLab }
+func (*BitbucketAuth) ContextType() ContextType       { return ContextTypeBitbucket }
+func (*BitbucketServerAuth) ContextType() ContextType { return ContextTypeBitbucketServer }
+func (*AzureDevOpsAuth) ContextType() ContextType     { return ContextTypeAzureDevOps }
+func (*GerritAuth) ContextType() ContextType          { return ContextTypeGerrit }
+func (*CodeCommitAuth) ContextType() ContextType      { return ContextTypeCodeCommit }
+
+func (c v1Context) CreateGitContext(ctx context.Context, gitContext *GitContext) (*GitContext, error) {
+	body, err := gitContext.body()
+	if err != nil {
+		return nil, fmt.Errorf("failed creating git context: %w", err)
+	}
+
+	res, err := c.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "POST",
+		Path:   "/api/contexts",
+		Body:   body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating git context: %w", err)
+	}
+
+	return unmarshalGitContext(res)
+}
+
+func (c v1Context) GetGitContext(ctx context.Context, name string, opt *ContextGetOptions) (*GitContext, error) {
+	if opt == nil {
+		opt = &ContextGetOptions{}
+	}
+
+	res, err := c.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "GET",
+		Path:   fmt.Sprintf("/api/contexts/%s", url.PathEscape(name)),
+		Query:  decryptQuery(opt.Decrypt),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed getting git context: %w", err)
+	}
+
+	return unmarshalGitContext(res)
+}
+
+func (c v1Context) ListGitContexts(ctx context.Context, opt *ContextListOptions) ([]GitContext, error) {
+	if opt == nil {
+		opt = &ContextListOptions{}
+	}
+
+	types := opt.Types
+	if len(types) == 0 {
+		types = gitContextTypes
+	}
+
+	query := decryptQuery(opt.Decrypt)
+	query["type"] = contextTypeStrings(types)
+	res, err := c.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "GET",
+		Path:   "/api/contexts",
+		Query:  query,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing git contexts: %w", err)
+	}
+
+	result := make([]GitContext, 0)
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling git contexts: %w", err)
+	}
+
+	return result, nil
+}
+
+func (c v1Context) SetDefaultGitContext(ctx context.Context, name string) error {
+	_, err := c.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "POST",
+		Path:   fmt.Sprintf("/api/contexts/%s/default", url.PathEscape(name)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed setting default git context: %w", err)
+	}
+
+	return nil
+}
+
+func (c v1Context) UpdateGitContext(ctx context.Context, gitContext *GitContext) (*GitContext, error) {
+	body, err := gitContext.body()
+	if err != nil {
+		return nil, fmt.Errorf("failed updating git context: %w", err)
+	}
+
+	res, err := c.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "PUT",
+		Path:   fmt.Sprintf("/api/contexts/%s", url.PathEscape(gitContext.Metadata.Name)),
+		Body:   body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed updating git context: %w", err)
+	}
+
+	return unmarshalGitContext(res)
+}
+
+// GitContextTypes returns the types of all git contexts
+func GitContextTypes() []ContextType {
+	return slices.Clone(gitContextTypes)
+}
+
+// IsGit returns true for the types of git contexts
+func (t ContextType) IsGit() bool {
+	return strings.HasPrefix(string(t), "git.")
+}
+
+func (s *GitContextSpec) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Type ContextType `json:"type"`
+		Data struct {
+			Auth           json.RawMessage `json:"auth"`
+			SharingPolicy  string          `json:"sharingPolicy"`
+			BehindFirewall bool            `json:"behindFirewall"`
+		} `json:"data"`
+	}{}
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	s.Type = raw.Type
+	s.Data = GitContextData{
+		SharingPolicy:  raw.Data.SharingPolicy,
+		BehindFirewall: raw.Data.BehindFirewall,
+	}
+	if len(raw.Data.Auth) == 0 || string(raw.Data.Auth) == "null" {
+		return nil
+	}
+
+	auth, err := newGitAuth(raw.Type)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(raw.Data.Auth, auth)
+	if err != nil {
+		return err
+	}
+
+	s.Data.Auth = auth
+	return nil
+}
+
+// LogValue redacts the credentials of the context
+func (c GitContext) LogValue() slog.Value {
+	attrs := []slog.Attr{}
+	if c.Spec.Data.Auth != nil {
+		fields := map[string]any{}
+		data, _ := json.Marshal(c.Spec.Data.Auth)
+		_ = json.Unmarshal(data, &fields)
+		for _, k := range sortedKeys(fields) {
+			v := fmt.Sprint(fields[k])
+			if slices.Contains(secretGitAuthFields, k) {
+				v = client.Redact(v)
+			}
+
+			attrs = append(attrs, slog.String(k, v))
+		}
+	}
+
+	return slog.GroupValue(
+		slog.String("name", c.Metadata.Name),
+		slog.String("type", string(c.Spec.effectiveType())),
+		slog.Attr{Key: "auth", Value: slog.GroupValue(attrs...)},
+	)
+}
+
+func (c *GitContext) body() (map[string]any, error) {
+	if c == nil || c.Metadata.Name == "" {
+		return nil, fmt.Errorf("missing name")
+	}
+
+	if c.Spec.Data.Auth == nil {
+		return nil, fmt.Errorf("missing auth")
+	}
+
+	t := c.Spec.effectiveType()
+	if t != c.Spec.Data.Auth.ContextType() {
+		return nil, fmt.Errorf("type %q does not match auth of type %q", t, c.Spec.Data.Auth.ContextType())
+	}
+
+	auth := map[string]any{}
+	data, err := json.Marshal(c.Spec.Data.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &auth)
+	if err != nil {
+		return nil, err
+	}
+
+	if auth["type"] == "" {
+		auth["type"] = defaultGitAuthType
+	}
+
+	spec := map[string]any{
+		"auth": auth,
+	}
+	if c.Spec.Data.SharingPolicy != "" {
+		spec["sharingPolicy"] = c.Spec.Data.SharingPolicy
+	}
+
+	if c.Spec.Data.BehindFirewall {
+		spec["behindFirewall"] = true
+	}
+
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "context",
+		"metadata": map[string]any{
+			"name": c.Metadata.Name,
+		},
+		"spec": map[string]any{
+			"type": t,
+			"data": spec,
+		},
+	}, nil
+}
+
+// effectiveType returns the type of the spec, or the type of its auth when it is not set
+func (s *GitContextSpec) effectiveType() ContextType {
+	if s.Type == "" && s.Data.Auth != nil {
+		return s.Data.Auth.ContextType()
+	}
+
+	return s.Type
+}
+
+func newGitAuth(t ContextType) (GitAuth, error) {
+	switch t {
+	case ContextTypeGitHub:
+		return &GitHubAuth{}, nil
+	case ContextTypeGitHubApp:
+		return &GitHubAppAuth{}, nil
+	case ContextTypeGitLab:
+		return &GitLabAuth{}, nil
+	case ContextTypeBitbucket:
+		return &BitbucketAuth{}, nil
+	case ContextTypeBitbucketServer:
+		return &BitbucketServerAuth{}, nil
+	case ContextTypeAzureDevOps:
+		return &AzureDevOpsAuth{}, nil
+	case ContextTypeGerrit:
+		return &GerritAuth{}, nil
+	case ContextTypeCodeCommit:
+		return &CodeCommitAuth{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown git context type %q", t)
+}
+
+func contextTypeStrings(types []ContextType) []string {
+	result := make([]string, len(types))
+	for i, t := range types {
+		result[i] = string(t)
+	}
+
+	return result
+}
+
+func unmarshalGitContext(res []byte) (*GitContext, error) {
+	result := &GitContext{}
+	err := json.Unmarshal(res, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling git context: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,183 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_v1Context_CreateGitContext(t *testing.T) {
+	tests := []struct {
+		name       string
+		gitContext *GitContext
+		wantBody   string
+		wantErr    string
+	}{
+		{
+			name: "should set the type from the auth",
+			gitContext: &GitContext{
+				Metadata: ContextMetadata{Name: "bitbucket"},
+				Spec: GitContextSpec{Data: GitContextData{
+					Auth:          &BitbucketAuth{Username: "user", Password: "app-password"},
+					SharingPolicy: "AccountAdmins",
+				}},
+			},
+			wantBody: `{"apiVersion": "v1", "kind": "context", "metadata": {"name": "bitbucket"}, "spec": {"type": "git.bitbucket", "data": {
+				"auth": {"type": "basic", "username": "user", "password": "app-password"},
+				"sharingPolicy": "AccountAdmins"
+			}}}`,
+		},
+		{
+			name: "should send codecommit auth",
+			gitContext: &GitContext{
+				Metadata: ContextMetadata{Name: "codecommit"},
+				Spec: GitContextSpec{Type: ContextTypeCodeCommit, Data: GitContextData{
+					Auth: &CodeCommitAuth{Region: "us-east-1", AccessKeyID: "key-id", SecretAccessKey: "secret-key"},
+				}},
+			},
+			wantBody: `{"apiVersion": "v1", "kind": "context", "metadata": {"name": "codecommit"}, "spec": {"type": "git.codecommit", "data": {
+				"auth": {"type": "basic", "region": "us-east-1", "accessKeyId": "key-id", "secretAccessKey": "secret-key"}
+			}}}`,
+		},
+		{
+			name: "should fail when the type does not match the auth",
+			gitContext: &GitContext{
+				Metadata: ContextMetadata{Name: "gerrit"},
+				Spec: GitContextSpec{Type: ContextTypeGitHub, Data: GitContextData{
+					Auth: &GerritAuth{ApiHost: "https://gerrit.host"},
+				}},
+			},
+			wantErr: `failed creating git context: type "git.github" does not match auth of type "git.gerrit"`,
+		},
+		{
+			name: "should fail without auth",
+			gitContext: &GitContext{
+				Metadata: ContextMetadata{Name: "github"},
+			},
+			wantErr: "failed creating git context: missing auth",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			if tt.wantErr == "" {
+				mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, "POST", req.Method)
+					assert.Equal(t, "/api/contexts", req.URL.Path)
+					body, _ := io.ReadAll(req.Body)
+					assert.JSONEq(t, tt.wantBody, string(body))
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader(string(body))),
+					}, nil
+				})
+			}
+
+			c := v1Context{
+				client: cfClient,
+			}
+			got, err := c.CreateGitContext(context.Background(), tt.gitContext)
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.gitContext.Spec.Data.Auth.ContextType(), got.Spec.Type)
+			assert.IsType(t, tt.gitContext.Spec.Data.Auth, got.Spec.Data.Auth)
+		})
+	}
+}
+
+func Test_v1Context_ListGitContexts(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/api/contexts", req.URL.Path)
+		assert.Equal(t, "", req.URL.Query().Get("decrypt"))
+		assert.Equal(t, contextTypeStrings(GitContextTypes()), req.URL.Query()["type"])
+		return &http.Response{
+			StatusCode: 200,
+			Body: io.NopCloser(strings.NewReader(`[
+				{"metadata": {"name": "azure"}, "spec": {"type": "git.azure", "data": {"auth": {"type": "basic", "organization": "some-org", "password": "*****"}}}},
+				{"metadata": {"name": "gerrit"}, "spec": {"type": "git.gerrit", "data": {"auth": {"type": "basic", "username": "user", "password": "*****", "apiHost": "https://gerrit.host"}}}},
+				{"metadata": {"name": "app"}, "spec": {"type": "git.github-app", "data": {"auth": {"type": "app", "appId": "1", "installationId": "2", "privateKey": "*****"}}}}
+			]`)),
+		}, nil
+	})
+
+	c := v1Context{
+		client: cfClient,
+	}
+	got, err := c.ListGitContexts(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &AzureDevOpsAuth{Type: "basic", Organization: "some-org", Password: "*****"}, got[0].Spec.Data.Auth)
+	assert.Equal(t, &GerritAuth{Type: "basic", Username: "user", Password: "*****", ApiHost: "https://gerrit.host"}, got[1].Spec.Data.Auth)
+	assert.Equal(t, &GitHubAppAuth{Type: "app", AppId: "1", InstallationId: "2", PrivateKey: "*****"}, got[2].Spec.Data.Auth)
+}
+
+func Test_v1Context_GetGitContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		res     string
+		want    GitAuth
+		wantErr string
+	}{
+		{
+			name: "should decode bitbucket server auth",
+			res:  `{"metadata": {"name": "stash"}, "spec": {"type": "git.bitbucket-server", "data": {"auth": {"type": "basic", "username": "user", "password": "pass", "apiHost": "https://stash.host"}}}}`,
+			want: &BitbucketServerAuth{Type: "basic", Username: "user", Password: "pass", ApiHost: "https://stash.host"},
+		},
+		{
+			name:    "should fail on a context that is not a git context",
+			res:     `{"metadata": {"name": "secret"}, "spec": {"type": "secret", "data": {"auth": {}}}}`,
+			wantErr: `failed unmarshaling git context: unknown git context type "secret"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, "/api/contexts/some-context", req.URL.Path)
+				assert.Equal(t, "decrypt=true", req.URL.RawQuery)
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(tt.res)),
+				}, nil
+			})
+
+			c := v1Context{
+				client: cfClient,
+			}
+			got, err := c.GetGitContext(context.Background(), "some-context", &ContextGetOptions{Decrypt: true})
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, got.Spec.Data.Auth)
+		})
+	}
+}
+
+func Test_v1Context_SetDefaultGitContext(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "POST", req.Method)
+		assert.Equal(t, "/api/contexts/some-context/default", req.URL.Path)
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil
+	})
+
+	c := v1Context{
+		client: cfClient,
+	}
+	err := c.SetDefaultGitContext(context.Background(), "some-context")
+	assert.NoError(t, err)
+}
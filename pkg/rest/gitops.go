@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/codefresh-io/go-sdk/pkg/client"
 )
 
 type (
+	// Deprecated: use GitopsV2API instead
 	GitopsAPI interface {
 		CreateEnvironment(name string, project string, application string, integration string) error
 		DeleteEnvironment(name string) error
@@ -19,9 +21,25 @@ type (
 		SendEvent(name string, props map[string]string) error
 	}
 
+	GitopsV2API interface {
+		CreateEnvironment(ctx context.Context, name string, project string, application string, integration string) error
+		DeleteEnvironment(ctx context.Context, name string) error
+		GetEnvironments(ctx context.Context) ([]CFEnvironment, error)
+		// GetEnvironmentsPages calls fn with each page of environments, until there are no more pages or fn returns an error
+		GetEnvironmentsPages(ctx context.Context, opt *EnvironmentListOptions, fn func(page []CFEnvironment) error) error
+		SendApplicationResources(ctx context.Context, resources *ApplicationResources) error
+		SendEnvironment(ctx context.Context, environment Environment) (map[string]any, error)
+		SendEvent(ctx context.Context, name string, props map[string]string) error
+	}
+
 	gitops struct {
 		client *client.CfClient
 	}
+
+	// gitopsShim implements the deprecated GitopsAPI
+	gitopsShim struct {
+		v2 *gitops
+	}
 	CodefreshEvent struct {
 		Event string            `json:"event"`
 		Props map[string]string `json:"props"`
@@ -31,6 +49,10 @@ type (
 		Docs []CFEnvironment `json:"docs"`
 	}
 
+	EnvironmentListOptions struct {
+		ListOptions
+	}
+
 	CFEnvironment struct {
 		Metadata struct {
 			Name string `json:"name"`
@@ -131,8 +153,8 @@ type (
 	}
 )
 
-func (a *gitops) CreateEnvironment(name string, project string, application string, integration string) error {
-	_, err := a.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (a *gitops) CreateEnvironment(ctx context.Context, name string, project string, application string, integration string) error {
+	_, err := a.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "POST",
 		Path:   "/api/environments-v2",
 		Body: &EnvironmentPayload{
@@ -148,13 +170,17 @@ func (a *gitops) CreateEnvironment(name string, project string, application stri
 			},
 		},
 	})
-	return err
+	if err != nil {
+		return fmt.Errorf("failed creating an environment: %w", err)
+	}
+
+	return nil
 }
 
-func (a *gitops) DeleteEnvironment(name string) error {
-	_, err := a.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (a *gitops) DeleteEnvironment(ctx context.Context, name string) error {
+	_, err := a.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "DELETE",
-		Path:   fmt.Sprintf("/api/environments-v2/%s", name),
+		Path:   fmt.Sprintf("/api/environments-v2/%s", url.PathEscape(name)),
 	})
 	if err != nil {
 		return fmt.Errorf("failed deleting an environment: %w", err)
@@ -163,10 +189,11 @@ func (a *gitops) DeleteEnvironment(name string) error {
 	return nil
 }
 
-func (a *gitops) GetEnvironments() ([]CFEnvironment, error) {
-	res, err := a.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (a *gitops) GetEnvironments(ctx context.Context) ([]CFEnvironment, error) {
+	res, err := a.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "GET",
-		Path:   "/api/environments-v2?plain=true&isEnvironment=false",
+		Path:   "/api/environments-v2",
+		Query:  environmentsQuery(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed getting environment list: %w", err)
@@ -176,8 +203,45 @@ func (a *gitops) GetEnvironments() ([]CFEnvironment, error) {
 	return result.Docs, json.Unmarshal(res, result)
 }
 
-func (a *gitops) SendApplicationResources(resources *ApplicationResources) error {
-	_, err := a.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (a *gitops) GetEnvironmentsPages(ctx context.Context, opt *EnvironmentListOptions, fn func(page []CFEnvironment) error) error {
+	if opt == nil {
+		opt = &EnvironmentListOptions{}
+	}
+
+	return forEachOffsetPage(ctx, &opt.ListOptions, func(ctx context.Context, limit, offset int) ([]CFEnvironment, int, error) {
+		query := environmentsQuery()
+		for k, v := range pageQuery(limit, offset) {
+			query[k] = v
+		}
+
+		res, err := a.client.RestAPI(ctx, &client.RequestOptions{
+			Method: "GET",
+			Path:   "/api/environments-v2",
+			Query:  query,
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed getting environment list: %w", err)
+		}
+
+		result := &MongoCFEnvWrapper{}
+		err = json.Unmarshal(res, result)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed unmarshaling environment list: %w", err)
+		}
+
+		return result.Docs, -1, nil
+	}, nil, fn)
+}
+
+func environmentsQuery() map[string]any {
+	return map[string]any{
+		"plain":         "true",
+		"isEnvironment": "false",
+	}
+}
+
+func (a *gitops) SendApplicationResources(ctx context.Context, resources *ApplicationResources) error {
+	_, err := a.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "POST",
 		Path:   "/api/gitops/resources",
 		Body:   &resources,
@@ -189,8 +253,8 @@ func (a *gitops) SendApplicationResources(resources *ApplicationResources) error
 	return nil
 }
 
-func (a *gitops) SendEnvironment(environment Environment) (map[string]any, error) {
-	res, err := a.client.RestAPI(context.TODO(), &client.RequestOptions{Method: "POST", Path: "/api/environments-v2/argo/events", Body: environment})
+func (a *gitops) SendEnvironment(ctx context.Context, environment Environment) (map[string]any, error) {
+	res, err := a.client.RestAPI(ctx, &client.RequestOptions{Method: "POST", Path: "/api/environments-v2/argo/events", Body: environment})
 	if err != nil {
 		return nil, fmt.Errorf("failed sending an environment: %w", err)
 	}
@@ -199,8 +263,8 @@ func (a *gitops) SendEnvironment(environment Environment) (map[string]any, error
 	return result, json.Unmarshal(res, &result)
 }
 
-func (a *gitops) SendEvent(name string, props map[string]string) error {
-	_, err := a.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (a *gitops) SendEvent(ctx context.Context, name string, props map[string]string) error {
+	_, err := a.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "POST",
 		Path:   "/api/gitops/system/events",
 		Body:   CodefreshEvent{Event: name, Props: props},
@@ -211,3 +275,27 @@ func (a *gitops) SendEvent(name string, props map[string]string) error {
 
 	return nil
 }
+
+func (a *gitopsShim) CreateEnvironment(name string, project string, application string, integration string) error {
+	return a.v2.CreateEnvironment(context.Background(), name, project, application, integration)
+}
+
+func (a *gitopsShim) DeleteEnvironment(name string) error {
+	return a.v2.DeleteEnvironment(context.Background(), name)
+}
+
+func (a *gitopsShim) GetEnvironments() ([]CFEnvironment, error) {
+	return a.v2.GetEnvironments(context.Background())
+}
+
+func (a *gitopsShim) SendApplicationResources(resources *ApplicationResources) error {
+	return a.v2.SendApplicationResources(context.Background(), resources)
+}
+
+func (a *gitopsShim) SendEnvironment(environment Environment) (map[string]any, error) {
+	return a.v2.SendEnvironment(context.Background(), environment)
+}
+
+func (a *gitopsShim) SendEvent(name string, props map[string]string) error {
+	return a.v2.SendEvent(context.Background(), name, props)
+}
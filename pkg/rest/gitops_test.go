@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+	"github.com/codefresh-io/go-sdk/pkg/mocks"
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_reportingCalls_Retry(t *testing.T) {
+	tests := []struct {
+		name      string
+		wantPath  string
+		call      func(cfClient *client.CfClient) error
+		wantCalls int
+		wantErr   string
+	}{
+		{
+			name:     "should retry argo heartbeat",
+			wantPath: "/api/argo-agent/some-integration/heartbeat",
+			call: func(cfClient *client.CfClient) error {
+				return (&argo{client: cfClient}).HeartBeat(context.Background(), "", "1.0.0", "some-integration")
+			},
+			wantCalls: 2,
+		},
+		{
+			name:     "should retry argo resources",
+			wantPath: "/api/argo-agent/some-integration",
+			call: func(cfClient *client.CfClient) error {
+				return (&argo{client: cfClient}).SendResources(context.Background(), "applications", []string{}, 0, "some-integration")
+			},
+			wantCalls: 2,
+		},
+		{
+			// the environment is recorded as an event, a retry could record it twice
+			name:     "should not retry gitops environment events",
+			wantPath: "/api/environments-v2/argo/events",
+			call: func(cfClient *client.CfClient) error {
+				_, err := (&gitops{client: cfClient}).SendEnvironment(context.Background(), Environment{})
+				return err
+			},
+			wantCalls: 1,
+			wantErr:   "failed sending an environment: API error: : unavailable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRT := mocks.NewMockRoundTripper(t)
+			cfClient := client.NewCfClientWithOptions(&client.ClientOptions{
+				Host:   "https://some.host",
+				Token:  "some-token",
+				Client: &http.Client{Transport: mockRT},
+				Retry:  &client.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			})
+			calls := 0
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				calls++
+				assert.Equal(t, "POST", req.Method)
+				assert.Equal(t, tt.wantPath, req.URL.Path)
+				if calls == 1 {
+					return &http.Response{
+						StatusCode: 503,
+						Header:     http.Header{},
+						Body:       io.NopCloser(strings.NewReader("unavailable")),
+					}, nil
+				}
+
+				return &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{},
+					Body:       io.NopCloser(strings.NewReader("{}")),
+				}, nil
+			})
+
+			err := tt.call(cfClient)
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func Test_gitops_DeleteEnvironment(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "DELETE", req.Method)
+		assert.Equal(t, "/api/environments-v2/some%2Fenvironment", req.URL.EscapedPath())
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader("{}")),
+		}, nil
+	})
+	g := &gitops{
+		client: cfClient,
+	}
+	err := g.DeleteEnvironment(context.Background(), "some/environment")
+	assert.NoError(t, err)
+}
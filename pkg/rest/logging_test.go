@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LogValue_RedactsSecrets(t *testing.T) {
+	password := "integration-password"
+	token := "integration-token"
+	contextPayload := ContextPayload{}
+	contextPayload.Metadata.Name = "github"
+	contextPayload.Spec.Data.Auth.Password = "context-password"
+	contextPayload.Spec.Data.Auth.SshPrivateKey = "ssh-private-key"
+	contextPayload.Spec.Data.Auth.PrivateKey = "app-private-key"
+	cluster := Cluster{Url: "https://cluster.host"}
+	cluster.Auth.Bearer = "cluster-bearer"
+
+	tests := []struct {
+		name        string
+		value       any
+		wantContain string
+		secrets     []string
+	}{
+		{
+			name:        "ContextPayload",
+			value:       contextPayload,
+			wantContain: "github",
+			secrets:     []string{"context-password", "ssh-private-key", "app-private-key"},
+		},
+		{
+			name:        "IntegrationPayloadData",
+			value:       IntegrationPayloadData{Name: "argo", Password: &password, Token: &token},
+			wantContain: "argo",
+			secrets:     []string{password, token},
+		},
+		{
+			name:        "Cluster",
+			value:       cluster,
+			wantContain: "https://cluster.host",
+			secrets:     []string{"cluster-bearer"},
+		},
+		{
+			name: "SharedContext",
+			value: SharedContext{
+				Metadata: ContextMetadata{Name: "some-secret"},
+				Spec:     SharedContextSpec{Type: ContextTypeSecret, Data: ConfigData{"TOKEN": "secret-value"}},
+			},
+			wantContain: "some-secret",
+			secrets:     []string{"secret-value"},
+		},
+		{
+			name: "SharedContext helm repository",
+			value: SharedContext{
+				Metadata: ContextMetadata{Name: "some-repo"},
+				Spec: SharedContextSpec{Type: ContextTypeHelmRepository, Data: HelmRepositoryData{
+					Variables: map[string]string{"HELMREPO_URL": "https://charts.host", "HELMREPO_PASSWORD": "helm-password"},
+				}},
+			},
+			wantContain: "https://charts.host",
+			secrets:     []string{"helm-password"},
+		},
+		{
+			name: "GitContext",
+			value: GitContext{
+				Metadata: ContextMetadata{Name: "codecommit"},
+				Spec: GitContextSpec{Data: GitContextData{
+					Auth: &CodeCommitAuth{Region: "us-east-1", AccessKeyID: "key-id", SecretAccessKey: "codecommit-secret"},
+				}},
+			},
+			wantContain: "us-east-1",
+			secrets:     []string{"codecommit-secret"},
+		},
+		{
+			name: "Registry",
+			value: Registry{
+				Name:     "some-gcr",
+				Provider: &GCRRegistry{Domain: "gcr.io", Keyfile: `{"private_key": "gcr-private-key"}`},
+			},
+			wantContain: "gcr.io",
+			secrets:     []string{"gcr-private-key"},
+		},
+		{
+			name:        "Token",
+			value:       Token{Name: "some-token", Value: "token-value"},
+			wantContain: "some-token",
+			secrets:     []string{"token-value"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, nil))
+			logger.Info("value", "value", tt.value)
+			out := buf.String()
+			assert.Contains(t, out, tt.wantContain)
+			assert.Contains(t, out, "[REDACTED]")
+			for _, secret := range tt.secrets {
+				assert.NotContains(t, out, secret)
+			}
+		})
+	}
+}
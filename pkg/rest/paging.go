@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+)
+
+type (
+	// ListOptions are the offset paging options of rest list endpoints
+	ListOptions struct {
+		// Limit is the number of items requested per page (default: 100)
+		Limit int
+		// Offset is the number of items to skip
+		Offset int
+		// MaxItems stops paging once this many items were returned (default: no limit)
+		MaxItems int
+	}
+
+	// offsetPageFetcher returns a single page, and the total number of items (or -1 when unknown)
+	offsetPageFetcher[T any] func(ctx context.Context, limit, offset int) (page []T, total int, err error)
+)
+
+const defaultPageLimit = 100
+
+func pageQuery(limit, offset int) map[string]any {
+	return map[string]any{
+		"limit":  strconv.Itoa(limit),
+		"offset": strconv.Itoa(offset),
+	}
+}
+
+// forEachOffsetPage fetches the pages of a list one by one, and calls fn with each page.
+// A server that ignores the limit returns everything at once, so a page that is larger than the limit is the last one.
+// A server that ignores the offset returns the same page again, so a page that starts with the same item as
+// the previous page, by idOf, is dropped and ends the list. Equal items are not necessarily the same item, so
+// for items without an id (nil idOf, or an empty id) only a page that is equal to the whole previous page ends the list.
+func forEachOffsetPage[T any](ctx context.Context, opt *ListOptions, fetch offsetPageFetcher[T], idOf func(T) string, fn func(page []T) error) error {
+	if opt == nil {
+		opt = &ListOptions{}
+	}
+
+	pageSize := opt.Limit
+	if pageSize <= 0 {
+		pageSize = defaultPageLimit
+	}
+
+	offset := opt.Offset
+	count := 0
+	previousFirst := ""
+	var previous []T
+	for {
+		limit := pageSize
+		if opt.MaxItems > 0 {
+			limit = min(limit, opt.MaxItems-count)
+		}
+
+		page, total, err := fetch(ctx, limit, offset)
+		if err != nil {
+			return err
+		}
+
+		if len(page) > 0 {
+			first := ""
+			if idOf != nil {
+				first = idOf(page[0])
+			}
+
+			if (first != "" && first == previousFirst) || (first == "" && reflect.DeepEqual(page, previous)) {
+				return nil
+			}
+
+			// fn may change the items of the page
+			previousFirst, previous = first, slices.Clone(page)
+		}
+
+		ignoredLimit := len(page) > limit
+		if opt.MaxItems > 0 && count+len(page) > opt.MaxItems {
+			page = page[:opt.MaxItems-count]
+		}
+
+		count += len(page)
+		offset += len(page)
+		if len(page) > 0 {
+			err = fn(page)
+			if errors.Is(err, client.ErrStopPaging) {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		done := ignoredLimit || len(page) < limit || (total >= 0 && offset >= total) || (opt.MaxItems > 0 && count >= opt.MaxItems)
+		if done {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+	"github.com/stretchr/testify/assert"
+)
+
+type pageRequest struct {
+	limit  int
+	offset int
+}
+
+// testOffsetFetcher serves total items, honoring limit and offset unless ignoreLimit or ignoreOffset is set
+func testOffsetFetcher(total int, withTotal, ignoreLimit, ignoreOffset bool, requests *[]pageRequest) offsetPageFetcher[int] {
+	return func(_ context.Context, limit, offset int) ([]int, int, error) {
+		*requests = append(*requests, pageRequest{limit, offset})
+		start, end := offset, min(offset+limit, total)
+		if ignoreLimit {
+			start, end = 0, total
+		}
+
+		if ignoreOffset {
+			start, end = 0, min(limit, total)
+		}
+
+		page := make([]int, 0)
+		for i := start; i < end; i++ {
+			// every tenth item is equal to the first one, so pages may start with equal items
+			if i%10 == 0 {
+				page = append(page, 0)
+			} else {
+				page = append(page, i)
+			}
+		}
+
+		if !withTotal {
+			return page, -1, nil
+		}
+
+		return page, total, nil
+	}
+}
+
+func Test_forEachOffsetPage(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        int
+		withTotal    bool
+		ignoreLimit  bool
+		ignoreOffset bool
+		withID       bool
+		opt          *ListOptions
+		wantItems    int
+		wantRequests []pageRequest
+	}{
+		{
+			name:         "should stop on a short page",
+			total:        250,
+			wantItems:    250,
+			wantRequests: []pageRequest{{100, 0}, {100, 100}, {100, 200}},
+		},
+		{
+			name:         "should stop when the total is reached",
+			total:        200,
+			withTotal:    true,
+			wantItems:    200,
+			wantRequests: []pageRequest{{100, 0}, {100, 100}},
+		},
+		{
+			name:         "should send one more request without a total",
+			total:        200,
+			wantItems:    200,
+			wantRequests: []pageRequest{{100, 0}, {100, 100}, {100, 200}},
+		},
+		{
+			name:         "should start at the offset with the limit",
+			total:        30,
+			opt:          &ListOptions{Limit: 10, Offset: 5},
+			wantItems:    25,
+			wantRequests: []pageRequest{{10, 5}, {10, 15}, {10, 25}},
+		},
+		{
+			name:         "should stop at max items",
+			total:        30,
+			opt:          &ListOptions{Limit: 10, MaxItems: 15},
+			wantItems:    15,
+			wantRequests: []pageRequest{{10, 0}, {5, 10}},
+		},
+		{
+			name:         "should stop when the server ignores the limit",
+			total:        250,
+			ignoreLimit:  true,
+			opt:          &ListOptions{Limit: 10},
+			wantItems:    250,
+			wantRequests: []pageRequest{{10, 0}},
+		},
+		{
+			name:         "should stop when the server ignores the offset",
+			total:        250,
+			ignoreOffset: true,
+			opt:          &ListOptions{Limit: 10},
+			wantItems:    10,
+			wantRequests: []pageRequest{{10, 0}, {10, 10}},
+		},
+		{
+			name:         "should stop when the server ignores the offset, by the id of the first item",
+			total:        250,
+			ignoreOffset: true,
+			withID:       true,
+			opt:          &ListOptions{Limit: 10},
+			wantItems:    10,
+			wantRequests: []pageRequest{{10, 0}, {10, 10}},
+		},
+		{
+			name:         "should not stop on pages that start with equal items without an id",
+			total:        25,
+			opt:          &ListOptions{Limit: 10},
+			wantItems:    25,
+			wantRequests: []pageRequest{{10, 0}, {10, 10}, {10, 20}},
+		},
+		{
+			name:         "should stop when the server ignores the offset and returns a total",
+			total:        250,
+			withTotal:    true,
+			ignoreOffset: true,
+			opt:          &ListOptions{Limit: 10},
+			wantItems:    10,
+			wantRequests: []pageRequest{{10, 0}, {10, 10}},
+		},
+		{
+			name:         "should respect max items when the server ignores the limit",
+			total:        250,
+			ignoreLimit:  true,
+			opt:          &ListOptions{Limit: 10, MaxItems: 20},
+			wantItems:    20,
+			wantRequests: []pageRequest{{10, 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []pageRequest
+			items := []int{}
+			var idOf func(int) string
+			if tt.withID {
+				idOf = strconv.Itoa
+			}
+
+			err := forEachOffsetPage(context.Background(), tt.opt, testOffsetFetcher(tt.total, tt.withTotal, tt.ignoreLimit, tt.ignoreOffset, &requests), idOf, func(page []int) error {
+				items = append(items, page...)
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Len(t, items, tt.wantItems)
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func Test_forEachOffsetPage_Stop(t *testing.T) {
+	var requests []pageRequest
+	err := forEachOffsetPage(context.Background(), nil, testOffsetFetcher(250, false, false, false, &requests), nil, func(page []int) error {
+		return client.ErrStopPaging
+	})
+	assert.NoError(t, err)
+	assert.Len(t, requests, 1)
+
+	someErr := errors.New("some error")
+	err = forEachOffsetPage(context.Background(), nil, testOffsetFetcher(250, false, false, false, &requests), nil, func(page []int) error {
+		return someErr
+	})
+	assert.ErrorIs(t, err, someErr)
+}
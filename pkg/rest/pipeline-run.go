@@ -0,0 +1,219 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+)
+
+type (
+	RunOptions struct {
+		Branch string
+		// SHA runs a specific commit of the branch
+		SHA       string
+		Variables map[string]string
+		// TriggerID runs the pipeline as if it was started by the trigger, with its repository, contexts and variables
+		TriggerID           string
+		NoCache             bool
+		NoCfCache           bool
+		ResetVolume         bool
+		EnableNotifications bool
+		// Skip runs all steps except these, cannot be used together with Only
+		Skip []string
+		// Only runs only these steps, cannot be used together with Skip
+		Only []string
+		// RuntimeEnvironment overrides the runtime environment of the pipeline
+		RuntimeEnvironment *PipelineRuntimeEnvironment
+		// Contexts are shared configuration contexts that are added to the pipeline contexts for this run
+		Contexts []string
+		// PackID overrides the resource package of the pipeline
+		PackID string
+		// Debug runs the build in debug mode
+		Debug bool
+		// Yaml overrides the pipeline yaml for this run
+		Yaml string
+	}
+
+	RunResult struct {
+		// WorkflowID is the id of the build, to be used with the WorkflowAPI
+		WorkflowID string
+	}
+)
+
+// ErrInvalidRunOptions is returned, wrapped, when the run options are rejected before sending the request
+var ErrInvalidRunOptions = errors.New("invalid run options")
+
+func (p *pipelineShim) Run(name string, options *RunOptions) (string, error) {
+	res, err := p.v2.Run(context.Background(), name, options)
+	if err != nil {
+		return "", err
+	}
+
+	return res.WorkflowID, nil
+}
+
+func (p *pipeline) Run(ctx context.Context, name string, options *RunOptions) (*RunResult, error) {
+	if options == nil {
+		options = &RunOptions{}
+	}
+
+	err := options.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "POST",
+		Path:   fmt.Sprintf("/api/pipelines/run/%s", url.PathEscape(name)),
+		Body:   options.body(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed running pipeline: %w", err)
+	}
+
+	result, err := parseRunResult(res)
+	if err != nil {
+		return nil, fmt.Errorf("failed running pipeline: %w", err)
+	}
+
+	return result, nil
+}
+
+// Validate returns an error wrapping ErrInvalidRunOptions when the options cannot be used together
+func (o *RunOptions) Validate() error {
+	if len(o.Skip) > 0 && len(o.Only) > 0 {
+		return fmt.Errorf("%w: skip and only cannot be used together", ErrInvalidRunOptions)
+	}
+
+	for _, step := range slices.Concat(o.Skip, o.Only) {
+		if step == "" {
+			return fmt.Errorf("%w: empty step name", ErrInvalidRunOptions)
+		}
+	}
+
+	if o.SHA != "" && o.Branch == "" {
+		return fmt.Errorf("%w: sha %q requires a branch", ErrInvalidRunOptions, o.SHA)
+	}
+
+	for k := range o.Variables {
+		if k == "" {
+			return fmt.Errorf("%w: empty variable name", ErrInvalidRunOptions)
+		}
+	}
+
+	for _, c := range o.Contexts {
+		if c == "" {
+			return fmt.Errorf("%w: empty context name", ErrInvalidRunOptions)
+		}
+	}
+
+	if o.RuntimeEnvironment != nil && o.RuntimeEnvironment.Name == "" {
+		return fmt.Errorf("%w: runtime environment requires a name", ErrInvalidRunOptions)
+	}
+
+	if o.Yaml != "" {
+		if _, err := UnmarshalPipelineWorkflow([]byte(o.Yaml)); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidRunOptions, err)
+		}
+	}
+
+	return nil
+}
+
+// body returns the run request, branch and variables are always sent, other fields only when set
+func (o *RunOptions) body() map[string]any {
+	body := map[string]any{
+		"branch":    o.Branch,
+		"variables": o.Variables,
+	}
+	if o.SHA != "" {
+		body["sha"] = o.SHA
+	}
+
+	if o.TriggerID != "" {
+		body["trigger"] = o.TriggerID
+	}
+
+	if o.NoCache || o.NoCfCache || o.ResetVolume || o.EnableNotifications {
+		body["options"] = map[string]bool{
+			"noCache":             o.NoCache,
+			"noCfCache":           o.NoCfCache,
+			"resetVolume":         o.ResetVolume,
+			"enableNotifications": o.EnableNotifications,
+		}
+	}
+
+	if len(o.Skip) > 0 {
+		body["skip"] = o.Skip
+	}
+
+	if len(o.Only) > 0 {
+		body["only"] = o.Only
+	}
+
+	if o.RuntimeEnvironment != nil {
+		body["runtimeEnvironment"] = o.RuntimeEnvironment
+	}
+
+	if len(o.Contexts) > 0 {
+		body["contexts"] = o.Contexts
+	}
+
+	if o.PackID != "" {
+		body["packId"] = o.PackID
+	}
+
+	if o.Debug {
+		body["isDebug"] = true
+	}
+
+	if o.Yaml != "" {
+		body["userYamlDescriptor"] = o.Yaml
+	}
+
+	return body
+}
+
+// parseRunResult accepts the build id as a json string (the current api), as an object with an id,
+// or as a bare id. It fails when the response has no id
+func parseRunResult(res []byte) (*RunResult, error) {
+	var id string
+	if json.Unmarshal(res, &id) == nil {
+		if id == "" {
+			return nil, fmt.Errorf("empty build id in response")
+		}
+
+		return &RunResult{WorkflowID: id}, nil
+	}
+
+	obj := struct {
+		ID         string `json:"id"`
+		WorkflowID string `json:"workflowId"`
+	}{}
+	if json.Unmarshal(res, &obj) == nil {
+		if obj.WorkflowID != "" {
+			return &RunResult{WorkflowID: obj.WorkflowID}, nil
+		}
+
+		if obj.ID != "" {
+			return &RunResult{WorkflowID: obj.ID}, nil
+		}
+	}
+
+	id = strings.TrimSpace(string(res))
+	if id == "" || strings.IndexFunc(id, isNotIDRune) >= 0 {
+		return nil, fmt.Errorf("no build id in response %q", string(res))
+	}
+
+	return &RunResult{WorkflowID: id}, nil
+}
+
+func isNotIDRune(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+}
@@ -0,0 +1,153 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_pipeline_Run(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  *RunOptions
+		response string
+		wantBody string
+		want     *RunResult
+		wantErr  string
+	}{
+		{
+			name:     "should send branch and variables without options",
+			response: `"some-build-id"`,
+			wantBody: `{"branch":"","variables":null}`,
+			want:     &RunResult{WorkflowID: "some-build-id"},
+		},
+		{
+			name: "should send all options",
+			options: &RunOptions{
+				Branch:             "main",
+				SHA:                "abc123",
+				Variables:          map[string]string{"KEY": "value"},
+				TriggerID:          "some-trigger",
+				NoCache:            true,
+				ResetVolume:        true,
+				Only:               []string{"build"},
+				RuntimeEnvironment: &PipelineRuntimeEnvironment{Name: "some-re", Memory: "2Gi"},
+				Contexts:           []string{"shared-config"},
+				PackID:             "some-pack",
+				Debug:              true,
+				Yaml:               "version: '1.0'\nsteps: {}\n",
+			},
+			response: `{"id": "some-build-id"}`,
+			wantBody: `{
+				"branch": "main",
+				"sha": "abc123",
+				"variables": {"KEY": "value"},
+				"trigger": "some-trigger",
+				"options": {"noCache": true, "noCfCache": false, "resetVolume": true, "enableNotifications": false},
+				"only": ["build"],
+				"runtimeEnvironment": {"name": "some-re", "memory": "2Gi"},
+				"contexts": ["shared-config"],
+				"packId": "some-pack",
+				"isDebug": true,
+				"userYamlDescriptor": "version: '1.0'\nsteps: {}\n"
+			}`,
+			want: &RunResult{WorkflowID: "some-build-id"},
+		},
+		{
+			name:     "should accept a bare build id",
+			response: "some-build-id\n",
+			wantBody: `{"branch":"","variables":null}`,
+			want:     &RunResult{WorkflowID: "some-build-id"},
+		},
+		{
+			name:     "should fail on a response without a build id",
+			response: `{"status": "ok"}`,
+			wantBody: `{"branch":"","variables":null}`,
+			wantErr:  `failed running pipeline: no build id in response "{\"status\": \"ok\"}"`,
+		},
+		{
+			name:     "should fail on an empty build id",
+			response: `""`,
+			wantBody: `{"branch":"","variables":null}`,
+			wantErr:  "failed running pipeline: empty build id in response",
+		},
+		{
+			name:    "should reject skip with only",
+			options: &RunOptions{Skip: []string{"a"}, Only: []string{"b"}},
+			wantErr: "invalid run options: skip and only cannot be used together",
+		},
+		{
+			name:    "should reject empty step names",
+			options: &RunOptions{Skip: []string{""}},
+			wantErr: "invalid run options: empty step name",
+		},
+		{
+			name:    "should reject sha without branch",
+			options: &RunOptions{SHA: "abc123"},
+			wantErr: `invalid run options: sha "abc123" requires a branch`,
+		},
+		{
+			name:    "should reject runtime environment without name",
+			options: &RunOptions{RuntimeEnvironment: &PipelineRuntimeEnvironment{CPU: "1"}},
+			wantErr: "invalid run options: runtime environment requires a name",
+		},
+		{
+			name:    "should reject invalid yaml",
+			options: &RunOptions{Yaml: "steps: [1"},
+			wantErr: "invalid run options: failed parsing pipeline yaml: yaml: line 1: did not find expected ',' or ']'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			if tt.wantBody != "" {
+				mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, "POST", req.Method)
+					assert.Equal(t, "/api/pipelines/run/some-project%2Fsome-pipeline", req.URL.EscapedPath())
+					body, _ := io.ReadAll(req.Body)
+					assert.JSONEq(t, tt.wantBody, string(body))
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader(tt.response)),
+					}, nil
+				})
+			}
+
+			p := &pipeline{
+				client: cfClient,
+			}
+			got, err := p.Run(context.Background(), "some-project/some-pipeline", tt.options)
+			if err != nil || tt.wantErr != "" {
+				if tt.wantBody == "" {
+					assert.ErrorIs(t, err, ErrInvalidRunOptions)
+				}
+
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_pipelineShim_Run(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/api/pipelines/run/some-pipeline", req.URL.EscapedPath())
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`"some-build-id"`)),
+		}, nil
+	})
+	p := NewRestClient(cfClient).Pipeline()
+	got, err := p.Run("some-pipeline", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "some-build-id", got)
+}
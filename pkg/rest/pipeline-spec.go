@@ -0,0 +1,402 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type (
+	PipelineSpec struct {
+		Triggers           []PipelineTrigger           `json:"triggers,omitempty"`
+		CronTriggers       []PipelineCronTrigger       `json:"cronTriggers,omitempty"`
+		Contexts           []string                    `json:"contexts,omitempty"`
+		Variables          []PipelineVariable          `json:"variables,omitempty"`
+		Steps              PipelineSteps               `json:"steps,omitempty"`
+		Stages             []string                    `json:"stages,omitempty"`
+		Mode               string                      `json:"mode,omitempty"`
+		SpecTemplate       *PipelineSpecTemplate       `json:"specTemplate,omitempty"`
+		RuntimeEnvironment *PipelineRuntimeEnvironment `json:"runtimeEnvironment,omitempty"`
+		Options            *PipelineOptions            `json:"options,omitempty"`
+		// Concurrency, BranchConcurrency and TriggerConcurrency are not sent when nil, a 0 is sent as is
+		Concurrency        *int `json:"concurrency,omitempty"`
+		BranchConcurrency  *int `json:"branchConcurrency,omitempty"`
+		TriggerConcurrency *int `json:"triggerConcurrency,omitempty"`
+		// Extra holds the fields that are not modeled, so they are sent back as they were received
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+
+	// PipelineTrigger is a git trigger of a pipeline
+	PipelineTrigger struct {
+		Name                         string             `json:"name,omitempty"`
+		Description                  string             `json:"description,omitempty"`
+		Type                         string             `json:"type"`
+		Repo                         string             `json:"repo"`
+		Events                       []string           `json:"events"`
+		Provider                     string             `json:"provider"`
+		Context                      string             `json:"context"`
+		Disabled                     bool               `json:"disabled,omitempty"`
+		Verified                     bool               `json:"verified,omitempty"`
+		BranchRegex                  string             `json:"branchRegex,omitempty"`
+		BranchRegexInput             string             `json:"branchRegexInput,omitempty"`
+		PullRequestTargetBranchRegex string             `json:"pullRequestTargetBranchRegex,omitempty"`
+		PullRequestAllowForkEvents   bool               `json:"pullRequestAllowForkEvents,omitempty"`
+		CommentRegex                 string             `json:"commentRegex,omitempty"`
+		ModifiedFilesGlob            string             `json:"modifiedFilesGlob,omitempty"`
+		Contexts                     []string           `json:"contexts,omitempty"`
+		Variables                    []PipelineVariable `json:"variables,omitempty"`
+		// RuntimeEnvironment overrides the runtime environment of the pipeline, for builds started by the trigger
+		RuntimeEnvironment *PipelineRuntimeEnvironment `json:"runtimeEnvironment,omitempty"`
+		// Extra holds the fields that are not modeled, so they are sent back as they were received
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+
+	// PipelineCronTrigger runs the pipeline on a schedule
+	PipelineCronTrigger struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+		// Expression is the cron expression of the schedule, e.g. "0 4 * * *"
+		Expression string `json:"expression"`
+		Message    string `json:"message,omitempty"`
+		Disabled   bool   `json:"disabled,omitempty"`
+		// GitTriggerID is the git trigger that selects the repository of the builds
+		GitTriggerID       string                      `json:"gitTriggerId,omitempty"`
+		Branch             string                      `json:"branch,omitempty"`
+		Variables          []PipelineVariable          `json:"variables,omitempty"`
+		RuntimeEnvironment *PipelineRuntimeEnvironment `json:"runtimeEnvironment,omitempty"`
+		// Extra holds the fields that are not modeled, so they are sent back as they were received
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+
+	PipelineVariable struct {
+		Key       string `json:"key"`
+		Value     string `json:"value"`
+		Encrypted bool   `json:"encrypted,omitempty"`
+	}
+
+	// PipelineSpecTemplate loads the pipeline steps from a file in a git repository
+	PipelineSpecTemplate struct {
+		Location string `json:"location,omitempty"`
+		Repo     string `json:"repo,omitempty"`
+		Path     string `json:"path,omitempty"`
+		Revision string `json:"revision,omitempty"`
+		Context  string `json:"context,omitempty"`
+	}
+
+	PipelineRuntimeEnvironment struct {
+		Name        string `json:"name,omitempty"`
+		CPU         string `json:"cpu,omitempty"`
+		Memory      string `json:"memory,omitempty"`
+		DindStorage string `json:"dindStorage,omitempty"`
+	}
+
+	PipelineOptions struct {
+		NoCache             bool `json:"noCache"`
+		NoCfCache           bool `json:"noCfCache"`
+		ResetVolume         bool `json:"resetVolume"`
+		EnableNotifications bool `json:"enableNotifications"`
+	}
+
+	// PipelineStep is a single step, its name is the key of the step in the steps object
+	PipelineStep struct {
+		Name             string         `json:"-"`
+		Type             string         `json:"type,omitempty"`
+		Title            string         `json:"title,omitempty"`
+		Description      string         `json:"description,omitempty"`
+		Stage            string         `json:"stage,omitempty"`
+		Image            string         `json:"image,omitempty"`
+		WorkingDirectory string         `json:"working_directory,omitempty"`
+		Commands         []string       `json:"commands,omitempty"`
+		Environment      []string       `json:"environment,omitempty"`
+		Arguments        map[string]any `json:"arguments,omitempty"`
+		When             map[string]any `json:"when,omitempty"`
+		FailFast         *bool          `json:"fail_fast,omitempty"`
+		Timeout          string         `json:"timeout,omitempty"`
+		// Steps are the nested steps of a parallel step
+		Steps PipelineSteps `json:"steps,omitempty"`
+		// Extra holds the fields that are not modeled (e.g. the arguments of a build step), so they are sent back as they were received
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+
+	// PipelineSteps is the ordered steps object of a pipeline
+	PipelineSteps []PipelineStep
+
+	// PipelineWorkflow is the content of the pipeline yaml (PipelineMetadata.OriginalYamlString)
+	PipelineWorkflow struct {
+		Version string        `json:"version,omitempty"`
+		Mode    string        `json:"mode,omitempty"`
+		Stages  []string      `json:"stages,omitempty"`
+		Steps   PipelineSteps `json:"steps,omitempty"`
+		// Extra holds the fields that are not modeled (e.g. hooks), so they are written back as they were read
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+
+	// the xxxFields types have the same fields, without the json methods
+	pipelineSpecFields        PipelineSpec
+	pipelineTriggerFields     PipelineTrigger
+	pipelineCronTriggerFields PipelineCronTrigger
+	pipelineStepFields        PipelineStep
+	pipelineWorkflowFields    PipelineWorkflow
+)
+
+// knownJSONFields caches the json keys of each struct type
+var knownJSONFields sync.Map
+
+func (s PipelineSpec) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(pipelineSpecFields(s), s.Extra)
+}
+
+func (s *PipelineSpec) UnmarshalJSON(data []byte) error {
+	fields := pipelineSpecFields{}
+	extra, err := unmarshalWithExtra(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	*s = PipelineSpec(fields)
+	s.Extra = extra
+	return nil
+}
+
+func (t PipelineTrigger) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(pipelineTriggerFields(t), t.Extra)
+}
+
+func (t *PipelineTrigger) UnmarshalJSON(data []byte) error {
+	fields := pipelineTriggerFields{}
+	extra, err := unmarshalWithExtra(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	*t = PipelineTrigger(fields)
+	t.Extra = extra
+	return nil
+}
+
+func (t PipelineCronTrigger) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(pipelineCronTriggerFields(t), t.Extra)
+}
+
+func (t *PipelineCronTrigger) UnmarshalJSON(data []byte) error {
+	fields := pipelineCronTriggerFields{}
+	extra, err := unmarshalWithExtra(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	*t = PipelineCronTrigger(fields)
+	t.Extra = extra
+	return nil
+}
+
+func (s PipelineStep) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(pipelineStepFields(s), s.Extra)
+}
+
+// UnmarshalJSON keeps the Name of the step, which is not part of the step object
+func (s *PipelineStep) UnmarshalJSON(data []byte) error {
+	fields := pipelineStepFields{}
+	extra, err := unmarshalWithExtra(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	fields.Name = s.Name
+	*s = PipelineStep(fields)
+	s.Extra = extra
+	return nil
+}
+
+// Get returns the step with the name, without looking in nested steps
+func (s PipelineSteps) Get(name string) (*PipelineStep, bool) {
+	for i := range s {
+		if s[i].Name == name {
+			return &s[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// MarshalJSON writes the steps as an object, in the order of the slice
+func (s PipelineSteps) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, step := range s {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		name, err := json.Marshal(step.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(step)
+		if err != nil {
+			return nil, fmt.Errorf("failed marshaling step %q: %w", step.Name, err)
+		}
+
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON reads the steps object, keeping the order of the steps
+func (s *PipelineSteps) UnmarshalJSON(data []byte) error {
+	if string(bytes.TrimSpace(data)) == "null" {
+		*s = nil
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("failed unmarshaling steps: expected an object, got %v", tok)
+	}
+
+	steps := PipelineSteps{}
+	for dec.More() {
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+
+		step := PipelineStep{Name: tok.(string)}
+		err = dec.Decode(&step)
+		if err != nil {
+			return fmt.Errorf("failed unmarshaling step %q: %w", step.Name, err)
+		}
+
+		steps = append(steps, step)
+	}
+
+	*s = steps
+	return nil
+}
+
+func (w PipelineWorkflow) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(pipelineWorkflowFields(w), w.Extra)
+}
+
+func (w *PipelineWorkflow) UnmarshalJSON(data []byte) error {
+	fields := pipelineWorkflowFields{}
+	extra, err := unmarshalWithExtra(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	*w = PipelineWorkflow(fields)
+	w.Extra = extra
+	return nil
+}
+
+// UnmarshalPipelineWorkflow parses a pipeline yaml, keeping the order of the steps
+func UnmarshalPipelineWorkflow(data []byte) (*PipelineWorkflow, error) {
+	j, err := yamlToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing pipeline yaml: %w", err)
+	}
+
+	w := &PipelineWorkflow{}
+	if string(j) == "null" {
+		return w, nil
+	}
+
+	err = json.Unmarshal(j, w)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling pipeline yaml: %w", err)
+	}
+
+	return w, nil
+}
+
+// MarshalPipelineWorkflow returns the pipeline yaml of the workflow, with the steps in their order
+func MarshalPipelineWorkflow(w *PipelineWorkflow) ([]byte, error) {
+	j, err := json.Marshal(w)
+	if err != nil {
+		return nil, fmt.Errorf("failed marshaling pipeline workflow: %w", err)
+	}
+
+	return jsonToYAML(j)
+}
+
+// marshalWithExtra marshals the fields of v, and adds the extra fields that v does not have
+func marshalWithExtra(v any, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, raw := range extra {
+		if _, ok := fields[k]; !ok {
+			fields[k] = raw
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// unmarshalWithExtra unmarshals data into v (a pointer to struct), and returns the fields that v does not have
+func unmarshalWithExtra(data []byte, v any) (map[string]json.RawMessage, error) {
+	err := json.Unmarshal(data, v)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for k := range jsonFieldsOf(reflect.TypeOf(v).Elem()) {
+		delete(fields, k)
+	}
+
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	return fields, nil
+}
+
+func jsonFieldsOf(t reflect.Type) map[string]bool {
+	if known, ok := knownJSONFields.Load(t); ok {
+		return known.(map[string]bool)
+	}
+
+	known := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+		if name != "" && name != "-" {
+			known[name] = true
+		}
+	}
+
+	knownJSONFields.Store(t, known)
+	return known
+}
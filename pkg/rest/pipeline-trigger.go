@@ -0,0 +1,266 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+)
+
+type (
+	// PipelineTriggerAPI manages the triggers in the spec of a pipeline. Triggers are identified by their name,
+	// and every change is a Modify of the pipeline, so it is not atomic (see PipelineAPI.Modify).
+	// The trigger passed to a change is not modified, the returned trigger is the one that was stored
+	PipelineTriggerAPI interface {
+		CreateCron(ctx context.Context, pipelineName string, trigger *PipelineCronTrigger) (*PipelineCronTrigger, error)
+		CreateGit(ctx context.Context, pipelineName string, trigger *PipelineTrigger) (*PipelineTrigger, error)
+		DeleteCron(ctx context.Context, pipelineName, triggerName string) error
+		DeleteGit(ctx context.Context, pipelineName, triggerName string) error
+		// Disable disables the git or cron trigger with the name
+		Disable(ctx context.Context, pipelineName, triggerName string) error
+		// Enable enables the git or cron trigger with the name
+		Enable(ctx context.Context, pipelineName, triggerName string) error
+		ListCron(ctx context.Context, pipelineName string) ([]PipelineCronTrigger, error)
+		ListGit(ctx context.Context, pipelineName string) ([]PipelineTrigger, error)
+		UpdateCron(ctx context.Context, pipelineName, triggerName string, trigger *PipelineCronTrigger) (*PipelineCronTrigger, error)
+		UpdateGit(ctx context.Context, pipelineName, triggerName string, trigger *PipelineTrigger) (*PipelineTrigger, error)
+	}
+
+	pipelineTrigger struct {
+		pipeline *pipeline
+	}
+)
+
+const (
+	PipelineTriggerTypeGit  = "git"
+	PipelineTriggerTypeCron = "cron"
+)
+
+func (p *pipeline) Triggers() PipelineTriggerAPI {
+	return &pipelineTrigger{pipeline: p}
+}
+
+func (t *pipelineTrigger) CreateCron(ctx context.Context, pipelineName string, trigger *PipelineCronTrigger) (*PipelineCronTrigger, error) {
+	if trigger.Expression == "" {
+		return nil, fmt.Errorf("failed creating cron trigger: missing expression")
+	}
+
+	created := *trigger
+	if created.Type == "" {
+		created.Type = PipelineTriggerTypeCron
+	}
+
+	err := t.patch(ctx, pipelineName, func(spec *PipelineSpec) error {
+		if err := checkNewTriggerName(spec, created.Name); err != nil {
+			return err
+		}
+
+		spec.CronTriggers = append(spec.CronTriggers, created)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating cron trigger: %w", err)
+	}
+
+	return &created, nil
+}
+
+func (t *pipelineTrigger) CreateGit(ctx context.Context, pipelineName string, trigger *PipelineTrigger) (*PipelineTrigger, error) {
+	if trigger.Repo == "" {
+		return nil, fmt.Errorf("failed creating git trigger: missing repo")
+	}
+
+	created := *trigger
+	if created.Type == "" {
+		created.Type = PipelineTriggerTypeGit
+	}
+
+	err := t.patch(ctx, pipelineName, func(spec *PipelineSpec) error {
+		if err := checkNewTriggerName(spec, created.Name); err != nil {
+			return err
+		}
+
+		spec.Triggers = append(spec.Triggers, created)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating git trigger: %w", err)
+	}
+
+	return &created, nil
+}
+
+func (t *pipelineTrigger) DeleteCron(ctx context.Context, pipelineName, triggerName string) error {
+	err := t.patch(ctx, pipelineName, func(spec *PipelineSpec) error {
+		i, err := findTrigger(spec.CronTriggers, triggerName, func(t PipelineCronTrigger) string { return t.Name })
+		if err != nil {
+			return err
+		}
+
+		spec.CronTriggers = append(spec.CronTriggers[:i], spec.CronTriggers[i+1:]...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed deleting cron trigger: %w", err)
+	}
+
+	return nil
+}
+
+func (t *pipelineTrigger) DeleteGit(ctx context.Context, pipelineName, triggerName string) error {
+	err := t.patch(ctx, pipelineName, func(spec *PipelineSpec) error {
+		i, err := findTrigger(spec.Triggers, triggerName, func(t PipelineTrigger) string { return t.Name })
+		if err != nil {
+			return err
+		}
+
+		spec.Triggers = append(spec.Triggers[:i], spec.Triggers[i+1:]...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed deleting git trigger: %w", err)
+	}
+
+	return nil
+}
+
+func (t *pipelineTrigger) Disable(ctx context.Context, pipelineName, triggerName string) error {
+	err := t.setDisabled(ctx, pipelineName, triggerName, true)
+	if err != nil {
+		return fmt.Errorf("failed disabling trigger: %w", err)
+	}
+
+	return nil
+}
+
+func (t *pipelineTrigger) Enable(ctx context.Context, pipelineName, triggerName string) error {
+	err := t.setDisabled(ctx, pipelineName, triggerName, false)
+	if err != nil {
+		return fmt.Errorf("failed enabling trigger: %w", err)
+	}
+
+	return nil
+}
+
+func (t *pipelineTrigger) ListCron(ctx context.Context, pipelineName string) ([]PipelineCronTrigger, error) {
+	pipeline, err := t.pipeline.Get(ctx, pipelineName)
+	if err != nil {
+		return nil, err
+	}
+
+	return pipeline.Spec.CronTriggers, nil
+}
+
+func (t *pipelineTrigger) ListGit(ctx context.Context, pipelineName string) ([]PipelineTrigger, error) {
+	pipeline, err := t.pipeline.Get(ctx, pipelineName)
+	if err != nil {
+		return nil, err
+	}
+
+	return pipeline.Spec.Triggers, nil
+}
+
+func (t *pipelineTrigger) UpdateCron(ctx context.Context, pipelineName, triggerName string, trigger *PipelineCronTrigger) (*PipelineCronTrigger, error) {
+	updated := *trigger
+	if updated.Type == "" {
+		updated.Type = PipelineTriggerTypeCron
+	}
+
+	err := t.patch(ctx, pipelineName, func(spec *PipelineSpec) error {
+		i, err := findTrigger(spec.CronTriggers, triggerName, func(t PipelineCronTrigger) string { return t.Name })
+		if err != nil {
+			return err
+		}
+
+		if updated.Name != triggerName {
+			if err = checkNewTriggerName(spec, updated.Name); err != nil {
+				return err
+			}
+		}
+
+		spec.CronTriggers[i] = updated
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed updating cron trigger: %w", err)
+	}
+
+	return &updated, nil
+}
+
+func (t *pipelineTrigger) UpdateGit(ctx context.Context, pipelineName, triggerName string, trigger *PipelineTrigger) (*PipelineTrigger, error) {
+	updated := *trigger
+	if updated.Type == "" {
+		updated.Type = PipelineTriggerTypeGit
+	}
+
+	err := t.patch(ctx, pipelineName, func(spec *PipelineSpec) error {
+		i, err := findTrigger(spec.Triggers, triggerName, func(t PipelineTrigger) string { return t.Name })
+		if err != nil {
+			return err
+		}
+
+		if updated.Name != triggerName {
+			if err = checkNewTriggerName(spec, updated.Name); err != nil {
+				return err
+			}
+		}
+
+		spec.Triggers[i] = updated
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed updating git trigger: %w", err)
+	}
+
+	return &updated, nil
+}
+
+func (t *pipelineTrigger) patch(ctx context.Context, pipelineName string, fn func(spec *PipelineSpec) error) error {
+	_, err := t.pipeline.Modify(ctx, pipelineName, func(pipeline *Pipeline) error {
+		return fn(&pipeline.Spec)
+	})
+	return err
+}
+
+func (t *pipelineTrigger) setDisabled(ctx context.Context, pipelineName, triggerName string, disabled bool) error {
+	return t.patch(ctx, pipelineName, func(spec *PipelineSpec) error {
+		if i, err := findTrigger(spec.Triggers, triggerName, func(t PipelineTrigger) string { return t.Name }); err == nil {
+			spec.Triggers[i].Disabled = disabled
+			return nil
+		}
+
+		i, err := findTrigger(spec.CronTriggers, triggerName, func(t PipelineCronTrigger) string { return t.Name })
+		if err != nil {
+			return err
+		}
+
+		spec.CronTriggers[i].Disabled = disabled
+		return nil
+	})
+}
+
+// checkNewTriggerName fails when the name is empty, or used by another git or cron trigger
+func checkNewTriggerName(spec *PipelineSpec, name string) error {
+	if name == "" {
+		return fmt.Errorf("missing trigger name")
+	}
+
+	_, gitErr := findTrigger(spec.Triggers, name, func(t PipelineTrigger) string { return t.Name })
+	_, cronErr := findTrigger(spec.CronTriggers, name, func(t PipelineCronTrigger) string { return t.Name })
+	if gitErr == nil || cronErr == nil {
+		return fmt.Errorf("trigger %q: %w", name, client.ErrConflict)
+	}
+
+	return nil
+}
+
+func findTrigger[T any](triggers []T, name string, nameOf func(T) string) (int, error) {
+	for i, t := range triggers {
+		if nameOf(t) == name {
+			return i, nil
+		}
+	}
+
+	return -1, fmt.Errorf("trigger %q: %w", name, client.ErrNotFound)
+}
@@ -0,0 +1,149 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_pipelineTrigger(t *testing.T) {
+	const stored = `{
+		"metadata": {"name": "some-pipeline"},
+		"spec": {
+			"triggers": [{"name": "push", "type": "git", "repo": "org/repo", "events": ["push.heads"], "provider": "github", "context": "github", "branchRegex": "/.*/gi"}],
+			"cronTriggers": [{"name": "nightly", "type": "cron", "expression": "0 4 * * *", "gitTriggerId": "some-id"}]
+		}
+	}`
+	tests := []struct {
+		name          string
+		action        func(api PipelineTriggerAPI) error
+		wantGit       []PipelineTrigger
+		wantCron      []PipelineCronTrigger
+		wantErr       string
+		wantErrTarget error
+	}{
+		{
+			name: "should create a git trigger",
+			action: func(api PipelineTriggerAPI) error {
+				trigger := &PipelineTrigger{
+					Name:                         "pr",
+					Repo:                         "org/repo",
+					Events:                       []string{"pullrequest.opened"},
+					PullRequestTargetBranchRegex: "/^main$/",
+					ModifiedFilesGlob:            "src/**",
+					Variables:                    []PipelineVariable{{Key: "A", Value: "1"}},
+					RuntimeEnvironment:           &PipelineRuntimeEnvironment{Name: "some-re"},
+				}
+				created, err := api.CreateGit(context.Background(), "some-pipeline", trigger)
+				if err != nil {
+					return err
+				}
+
+				// the trigger of the caller is not modified
+				if trigger.Type != "" || created.Type != PipelineTriggerTypeGit {
+					return fmt.Errorf("unexpected trigger types %q, %q", trigger.Type, created.Type)
+				}
+
+				return nil
+			},
+			wantGit: []PipelineTrigger{
+				{Name: "push", Type: "git", Repo: "org/repo", Events: []string{"push.heads"}, Provider: "github", Context: "github", BranchRegex: "/.*/gi"},
+				{
+					Name:                         "pr",
+					Type:                         "git",
+					Repo:                         "org/repo",
+					Events:                       []string{"pullrequest.opened"},
+					PullRequestTargetBranchRegex: "/^main$/",
+					ModifiedFilesGlob:            "src/**",
+					Variables:                    []PipelineVariable{{Key: "A", Value: "1"}},
+					RuntimeEnvironment:           &PipelineRuntimeEnvironment{Name: "some-re"},
+				},
+			},
+			wantCron: []PipelineCronTrigger{{Name: "nightly", Type: "cron", Expression: "0 4 * * *", GitTriggerID: "some-id"}},
+		},
+		{
+			name: "should reject a duplicate trigger name",
+			action: func(api PipelineTriggerAPI) error {
+				_, err := api.CreateCron(context.Background(), "some-pipeline", &PipelineCronTrigger{Name: "push", Expression: "* * * * *"})
+				return err
+			},
+			wantErr:       `failed creating cron trigger: failed modifying pipeline: trigger "push": conflict`,
+			wantErrTarget: client.ErrConflict,
+		},
+		{
+			name: "should update a cron trigger",
+			action: func(api PipelineTriggerAPI) error {
+				_, err := api.UpdateCron(context.Background(), "some-pipeline", "nightly", &PipelineCronTrigger{Name: "nightly", Expression: "0 2 * * *", Branch: "main"})
+				return err
+			},
+			wantGit:  []PipelineTrigger{{Name: "push", Type: "git", Repo: "org/repo", Events: []string{"push.heads"}, Provider: "github", Context: "github", BranchRegex: "/.*/gi"}},
+			wantCron: []PipelineCronTrigger{{Name: "nightly", Type: "cron", Expression: "0 2 * * *", Branch: "main"}},
+		},
+		{
+			name: "should fail updating a missing trigger",
+			action: func(api PipelineTriggerAPI) error {
+				_, err := api.UpdateGit(context.Background(), "some-pipeline", "missing", &PipelineTrigger{Name: "missing"})
+				return err
+			},
+			wantErr:       `failed updating git trigger: failed modifying pipeline: trigger "missing": not found`,
+			wantErrTarget: client.ErrNotFound,
+		},
+		{
+			name: "should delete a git trigger",
+			action: func(api PipelineTriggerAPI) error {
+				return api.DeleteGit(context.Background(), "some-pipeline", "push")
+			},
+			wantCron: []PipelineCronTrigger{{Name: "nightly", Type: "cron", Expression: "0 4 * * *", GitTriggerID: "some-id"}},
+		},
+		{
+			name: "should disable a cron trigger",
+			action: func(api PipelineTriggerAPI) error {
+				return api.Disable(context.Background(), "some-pipeline", "nightly")
+			},
+			wantGit:  []PipelineTrigger{{Name: "push", Type: "git", Repo: "org/repo", Events: []string{"push.heads"}, Provider: "github", Context: "github", BranchRegex: "/.*/gi"}},
+			wantCron: []PipelineCronTrigger{{Name: "nightly", Type: "cron", Expression: "0 4 * * *", GitTriggerID: "some-id", Disabled: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			var replaced *Pipeline
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, "/api/pipelines/some-pipeline", req.URL.Path)
+				body := stored
+				if req.Method == "PUT" {
+					b, _ := io.ReadAll(req.Body)
+					replaced = &Pipeline{}
+					assert.NoError(t, json.Unmarshal(b, replaced))
+					body = string(b)
+				}
+
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(body)),
+				}, nil
+			})
+
+			api := (&pipeline{client: cfClient}).Triggers()
+			err := tt.action(api)
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.ErrorIs(t, err, tt.wantErrTarget)
+				assert.Nil(t, replaced)
+				return
+			}
+
+			assert.Equal(t, tt.wantGit, replaced.Spec.Triggers)
+			assert.Equal(t, tt.wantCron, replaced.Spec.CronTriggers)
+		})
+	}
+}
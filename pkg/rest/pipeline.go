@@ -3,24 +3,56 @@ package rest
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
-	"strings"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/codefresh-io/go-sdk/pkg/client"
 )
 
 type (
+	// Deprecated: use PipelineV2API instead
 	PipelineAPI interface {
 		List(query map[string]string) ([]Pipeline, error)
 		Run(string, *RunOptions) (string, error)
 	}
 
+	PipelineV2API interface {
+		// Apply creates or replaces the pipeline from its yaml (or json) definition
+		Apply(ctx context.Context, data []byte) (*Pipeline, error)
+		Create(ctx context.Context, pipeline *Pipeline) (*Pipeline, error)
+		Delete(ctx context.Context, name string) error
+		Get(ctx context.Context, name string) (*Pipeline, error)
+		// List returns a single page of pipelines, use ListPages to get all of them
+		List(ctx context.Context, opt *PipelineListOptions) ([]Pipeline, error)
+		// ListPages calls fn with each page of pipelines, until there are no more pages or fn returns an error
+		ListPages(ctx context.Context, opt *PipelineListOptions, fn func(page []Pipeline) error) error
+		// Modify gets the pipeline, calls fn to change it, and replaces it. Fields that are not modeled are kept as is.
+		// It stands in for a Patch, which the api does not support.
+		// The api has no conditional update, so Modify is not atomic: right before the replace it gets the pipeline again,
+		// and starts over (calling fn again) when its updated_at changed, failing with client.ErrConflict after 3 attempts.
+		// A change made between that check and the replace is still overwritten
+		Modify(ctx context.Context, name string, fn func(pipeline *Pipeline) error) (*Pipeline, error)
+		// Replace updates the whole pipeline
+		Replace(ctx context.Context, name string, pipeline *Pipeline) (*Pipeline, error)
+		// Run validates the options and starts a build of the pipeline
+		Run(ctx context.Context, name string, options *RunOptions) (*RunResult, error)
+		// Triggers manages the git and cron triggers of pipelines
+		Triggers() PipelineTriggerAPI
+	}
+
 	pipeline struct {
 		client *client.CfClient
 	}
 
+	// pipelineShim implements the deprecated PipelineAPI
+	pipelineShim struct {
+		v2 *pipeline
+	}
+
 	PipelineMetadata struct {
 		Name     string `json:"name"`
 		IsPublic bool   `json:"isPublic"`
@@ -36,28 +68,16 @@ type (
 		CreatedAt          time.Time `json:"created_at"`
 		UpdatedAt          time.Time `json:"updated_at"`
 		Project            string    `json:"project"`
-		ID                 string    `json:"id"`
-	}
-
-	PipelineSpec struct {
-		Triggers []struct {
-			Type     string   `json:"type"`
-			Repo     string   `json:"repo"`
-			Events   []string `json:"events"`
-			Provider string   `json:"provider"`
-			Context  string   `json:"context"`
-		} `json:"triggers"`
-		Contexts  []any `json:"contexts"`
-		Variables []struct {
-			Key   string `json:"key"`
-			Value string `json:"value"`
-		} `json:"variables"`
-		Steps  map[string]any `json:"steps"`
-		Stages []any          `json:"stages"`
-		Mode   string         `json:"mode"`
+		ID                 string    `json:"id,omitempty"`
+		// Extra holds the fields that are not modeled, so they are sent back as they were received
+		Extra map[string]json.RawMessage `json:"-"`
 	}
 
+	pipelineMetadataFields PipelineMetadata
+
 	Pipeline struct {
+		Version  string           `json:"version,omitempty"`
+		Kind     string           `json:"kind,omitempty"`
 		Metadata PipelineMetadata `json:"metadata"`
 		Spec     PipelineSpec     `json:"spec"`
 	}
@@ -67,48 +87,333 @@ type (
 		Count int        `json:"count"`
 	}
 
-	RunOptions struct {
-		Branch    string
-		Variables map[string]string
+	PipelineListOptions struct {
+		ListOptions
+		// NameRegex filters pipelines whose name matches the regular expression
+		NameRegex string
+		// ProjectID filters the pipelines of a project
+		ProjectID string
+		// Tags filters pipelines that have all of the tags
+		Tags []string
+		// Labels filters pipelines that have all of the labels
+		Labels map[string]string
+		// Sort is the field to sort by, prefixed with "-" for descending order (e.g. "-metadata.created_at")
+		Sort string
+		// Projection selects full (default) or minified pipelines
+		Projection PipelineProjection
 	}
+
+	PipelineProjection string
 )
 
-// Get - returns pipelines from API
-func (p *pipeline) List(query map[string]string) ([]Pipeline, error) {
-	anyQuery := map[string]any{}
-	for k, v := range query {
-		anyQuery[k] = v
+const (
+	PipelineProjectionFull PipelineProjection = ""
+	// PipelineProjectionMinified returns the pipelines without their spec
+	PipelineProjectionMinified PipelineProjection = "minified"
+)
+
+// modifyAttempts is the number of times Modify starts over when the pipeline is updated by someone else
+const modifyAttempts = 3
+
+func (p *pipeline) Apply(ctx context.Context, data []byte) (*Pipeline, error) {
+	pipeline, err := UnmarshalPipeline(data)
+	if err != nil {
+		return nil, err
+	}
+
+	name := pipeline.Metadata.Name
+	if name == "" {
+		return nil, fmt.Errorf("failed applying pipeline: missing metadata.name")
+	}
+
+	_, err = p.Get(ctx, name)
+	if errors.Is(err, client.ErrNotFound) {
+		return p.Create(ctx, pipeline)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Replace(ctx, name, pipeline)
+}
+
+func (p *pipeline) Create(ctx context.Context, pipeline *Pipeline) (*Pipeline, error) {
+	res, err := p.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "POST",
+		Path:   "/api/pipelines",
+		Body:   pipeline,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating pipeline: %w", err)
+	}
+
+	return unmarshalPipelineResponse(res)
+}
+
+func (p *pipeline) Delete(ctx context.Context, name string) error {
+	_, err := p.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "DELETE",
+		Path:   fmt.Sprintf("/api/pipelines/%s", url.PathEscape(name)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed deleting pipeline: %w", err)
 	}
 
-	res, err := p.client.RestAPI(context.TODO(), &client.RequestOptions{
+	return nil
+}
+
+func (p *pipeline) Get(ctx context.Context, name string) (*Pipeline, error) {
+	res, err := p.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "GET",
+		Path:   fmt.Sprintf("/api/pipelines/%s", url.PathEscape(name)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed getting pipeline: %w", err)
+	}
+
+	return unmarshalPipelineResponse(res)
+}
+
+func (p *pipeline) List(ctx context.Context, opt *PipelineListOptions) ([]Pipeline, error) {
+	if opt == nil {
+		opt = &PipelineListOptions{}
+	}
+
+	query := opt.query()
+	if opt.Limit > 0 {
+		query["limit"] = strconv.Itoa(opt.Limit)
+	}
+
+	if opt.Offset > 0 {
+		query["offset"] = strconv.Itoa(opt.Offset)
+	}
+
+	result, err := p.list(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Docs, nil
+}
+
+func (p *pipeline) ListPages(ctx context.Context, opt *PipelineListOptions, fn func(page []Pipeline) error) error {
+	if opt == nil {
+		opt = &PipelineListOptions{}
+	}
+
+	return forEachOffsetPage(ctx, &opt.ListOptions, func(ctx context.Context, limit, offset int) ([]Pipeline, int, error) {
+		query := opt.query()
+		for k, v := range pageQuery(limit, offset) {
+			query[k] = v
+		}
+
+		result, err := p.list(ctx, query)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return result.Docs, result.Count, nil
+	}, func(p Pipeline) string { return p.Metadata.ID }, fn)
+}
+
+func (p *pipeline) list(ctx context.Context, query map[string]any) (*getPipelineResponse, error) {
+	res, err := p.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "GET",
 		Path:   "/api/pipelines",
-		Query:  anyQuery,
+		Query:  query,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed getting pipeline list: %w", err)
 	}
 
 	result := &getPipelineResponse{}
-	return result.Docs, json.Unmarshal(res, result)
+	err = json.Unmarshal(res, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling pipeline list: %w", err)
+	}
+
+	return result, nil
 }
 
-func (p *pipeline) Run(name string, options *RunOptions) (string, error) {
-	if options == nil {
-		options = &RunOptions{}
+// List returns the pipelines that match the query
+func (p *pipelineShim) List(query map[string]string) ([]Pipeline, error) {
+	anyQuery := map[string]any{}
+	for k, v := range query {
+		anyQuery[k] = v
 	}
 
-	res, err := p.client.RestAPI(context.TODO(), &client.RequestOptions{
-		Method: "POST",
-		Path:   fmt.Sprintf("/api/pipelines/run/%s", url.PathEscape(name)),
-		Body: map[string]any{
-			"branch":    options.Branch,
-			"variables": options.Variables,
-		},
+	result, err := p.v2.list(context.Background(), anyQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Docs, nil
+}
+
+// query returns the filters of the options as api query params, without limit and offset
+func (o *PipelineListOptions) query() map[string]any {
+	query := map[string]any{}
+	if o.NameRegex != "" {
+		query["nameRegex"] = o.NameRegex
+	}
+
+	if o.ProjectID != "" {
+		query["projectId"] = o.ProjectID
+	}
+
+	labels := make([]string, 0, len(o.Tags)+len(o.Labels))
+	for _, tag := range o.Tags {
+		labels = append(labels, "tag="+tag)
+	}
+
+	keys := make([]string, 0, len(o.Labels))
+	for k := range o.Labels {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		labels = append(labels, k+"="+o.Labels[k])
+	}
+
+	if len(labels) > 0 {
+		query["labels"] = labels
+	}
+
+	if o.Sort != "" {
+		query["sort"] = o.Sort
+	}
+
+	if o.Projection != PipelineProjectionFull {
+		query["projection"] = string(o.Projection)
+	}
+
+	return query
+}
+
+func (p *pipeline) Modify(ctx context.Context, name string, fn func(pipeline *Pipeline) error) (*Pipeline, error) {
+	for attempt := 1; ; attempt++ {
+		pipeline, err := p.Get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		updatedAt := pipeline.Metadata.UpdatedAt
+		err = fn(pipeline)
+		if err != nil {
+			return nil, fmt.Errorf("failed modifying pipeline: %w", err)
+		}
+
+		current, err := p.Get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if current.Metadata.UpdatedAt.Equal(updatedAt) {
+			return p.Replace(ctx, name, pipeline)
+		}
+
+		if attempt >= modifyAttempts {
+			return nil, fmt.Errorf("failed modifying pipeline: %q was updated while it was modified: %w", name, client.ErrConflict)
+		}
+	}
+}
+
+func (p *pipeline) Replace(ctx context.Context, name string, pipeline *Pipeline) (*Pipeline, error) {
+	res, err := p.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "PUT",
+		Path:   fmt.Sprintf("/api/pipelines/%s", url.PathEscape(name)),
+		Body:   pipeline,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed running pipeline: %w", err)
+		return nil, fmt.Errorf("failed replacing pipeline: %w", err)
+	}
+
+	return unmarshalPipelineResponse(res)
+}
+
+func unmarshalPipelineResponse(res []byte) (*Pipeline, error) {
+	result := &Pipeline{}
+	err := json.Unmarshal(res, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling pipeline: %w", err)
+	}
+
+	return result, nil
+}
+
+// UnmarshalPipeline parses a pipeline definition, in yaml or json, keeping the order of the steps
+func UnmarshalPipeline(data []byte) (*Pipeline, error) {
+	j, err := yamlToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing pipeline: %w", err)
+	}
+
+	pipeline := &Pipeline{}
+	err = json.Unmarshal(j, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling pipeline: %w", err)
+	}
+
+	return pipeline, nil
+}
+
+// Workflow parses the pipeline yaml (Metadata.OriginalYamlString)
+func (p *Pipeline) Workflow() (*PipelineWorkflow, error) {
+	return UnmarshalPipelineWorkflow([]byte(p.Metadata.OriginalYamlString))
+}
+
+// SetWorkflow writes the workflow to the pipeline yaml (Metadata.OriginalYamlString),
+// and copies its steps, stages and mode to the spec, so both stay in sync
+func (p *Pipeline) SetWorkflow(w *PipelineWorkflow) error {
+	data, err := MarshalPipelineWorkflow(w)
+	if err != nil {
+		return err
+	}
+
+	p.Metadata.OriginalYamlString = string(data)
+	p.Spec.Steps = w.Steps
+	p.Spec.Stages = w.Stages
+	if w.Mode != "" {
+		p.Spec.Mode = w.Mode
+	}
+
+	return nil
+}
+
+func (m PipelineMetadata) MarshalJSON() ([]byte, error) {
+	data, err := marshalWithExtra(pipelineMetadataFields(m), m.Extra)
+	if err != nil || (!m.CreatedAt.IsZero() && !m.UpdatedAt.IsZero()) {
+		return data, err
+	}
+
+	// the timestamps are set by the server, so zero timestamps are not sent
+	fields := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.CreatedAt.IsZero() {
+		delete(fields, "created_at")
+	}
+
+	if m.UpdatedAt.IsZero() {
+		delete(fields, "updated_at")
+	}
+
+	return json.Marshal(fields)
+}
+
+func (m *PipelineMetadata) UnmarshalJSON(data []byte) error {
+	fields := pipelineMetadataFields{}
+	extra, err := unmarshalWithExtra(data, &fields)
+	if err != nil {
+		return err
 	}
 
-	return strings.Replace(string(res), "\"", "", -1), nil
+	*m = PipelineMetadata(fields)
+	m.Extra = extra
+	return nil
 }
@@ -0,0 +1,426 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+	"github.com/codefresh-io/go-sdk/pkg/mocks"
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_pipeline_ListPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		opt       *PipelineListOptions
+		wantNames []string
+		wantErr   string
+		beforeFn  func(rt *mocks.MockRoundTripper)
+	}{
+		{
+			name:      "should page until count is reached",
+			opt:       &PipelineListOptions{ListOptions: ListOptions{Limit: 2}},
+			wantNames: []string{"p0", "p1", "p2"},
+			beforeFn: func(rt *mocks.MockRoundTripper) {
+				rt.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, "/api/pipelines", req.URL.Path)
+					assert.Equal(t, "2", req.URL.Query().Get("limit"))
+					body := `{"docs": [{"metadata": {"name": "p0"}}, {"metadata": {"name": "p1"}}], "count": 3}`
+					if req.URL.Query().Get("offset") == "2" {
+						body = `{"docs": [{"metadata": {"name": "p2"}}], "count": 3}`
+					}
+
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader(body)),
+					}, nil
+				}).Times(2)
+			},
+		},
+		{
+			name:    "should fail on error response",
+			wantErr: "failed getting pipeline list: API error: : some error",
+			beforeFn: func(rt *mocks.MockRoundTripper) {
+				rt.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).Return(&http.Response{
+					StatusCode: 500,
+					Body:       io.NopCloser(strings.NewReader("some error")),
+				}, nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			if tt.beforeFn != nil {
+				tt.beforeFn(mockRT)
+			}
+
+			p := &pipeline{
+				client: cfClient,
+			}
+			names := []string{}
+			err := p.ListPages(context.Background(), tt.opt, func(page []Pipeline) error {
+				for _, pipeline := range page {
+					names = append(names, pipeline.Metadata.Name)
+				}
+
+				return nil
+			})
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+}
+
+func Test_pipeline_List(t *testing.T) {
+	tests := []struct {
+		name      string
+		opt       *PipelineListOptions
+		wantQuery string
+	}{
+		{
+			name:      "should send no query without options",
+			wantQuery: "",
+		},
+		{
+			name: "should encode limit and offset",
+			opt: &PipelineListOptions{
+				ListOptions: ListOptions{Limit: 20, Offset: 40},
+			},
+			wantQuery: "limit=20&offset=40",
+		},
+		{
+			name: "should encode name regex and project",
+			opt: &PipelineListOptions{
+				NameRegex: "^deploy-.*$",
+				ProjectID: "some-project",
+			},
+			wantQuery: "nameRegex=%5Edeploy-.%2A%24&projectId=some-project",
+		},
+		{
+			name: "should encode tags and sorted labels",
+			opt: &PipelineListOptions{
+				Tags:   []string{"prod", "team-a"},
+				Labels: map[string]string{"zone": "eu", "app": "web"},
+			},
+			wantQuery: "labels=tag%3Dprod&labels=tag%3Dteam-a&labels=app%3Dweb&labels=zone%3Deu",
+		},
+		{
+			name: "should encode sort and minified projection",
+			opt: &PipelineListOptions{
+				Sort:       "-metadata.created_at",
+				Projection: PipelineProjectionMinified,
+			},
+			wantQuery: "projection=minified&sort=-metadata.created_at",
+		},
+		{
+			name: "should encode all options",
+			opt: &PipelineListOptions{
+				ListOptions: ListOptions{Limit: 5},
+				NameRegex:   "web",
+				ProjectID:   "p1",
+				Tags:        []string{"prod"},
+				Sort:        "metadata.name",
+			},
+			wantQuery: "labels=tag%3Dprod&limit=5&nameRegex=web&projectId=p1&sort=metadata.name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, "/api/pipelines", req.URL.Path)
+				assert.Equal(t, tt.wantQuery, req.URL.RawQuery)
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(`{"docs": [{"metadata": {"name": "p0"}}], "count": 1}`)),
+				}, nil
+			})
+
+			p := &pipeline{
+				client: cfClient,
+			}
+			got, err := p.List(context.Background(), tt.opt)
+			assert.NoError(t, err)
+			assert.Len(t, got, 1)
+		})
+	}
+}
+
+func Test_pipeline_Apply(t *testing.T) {
+	const data = `
+version: "1.0"
+kind: pipeline
+metadata:
+  name: some-project/some-pipeline
+spec:
+  steps:
+    clone:
+      type: git-clone
+      repo: org/repo
+    build:
+      type: build
+      image_name: org/image
+`
+	tests := []struct {
+		name       string
+		getStatus  int
+		wantMethod string
+		wantPath   string
+		wantErr    string
+	}{
+		{
+			name:       "should create a missing pipeline",
+			getStatus:  404,
+			wantMethod: "POST",
+			wantPath:   "/api/pipelines",
+		},
+		{
+			name:       "should replace an existing pipeline",
+			getStatus:  200,
+			wantMethod: "PUT",
+			wantPath:   "/api/pipelines/some-project%2Fsome-pipeline",
+		},
+		{
+			name:      "should fail when get fails",
+			getStatus: 500,
+			wantErr:   "failed getting pipeline: API error: : some error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				if req.Method == "GET" {
+					assert.Equal(t, "/api/pipelines/some-project%2Fsome-pipeline", req.URL.EscapedPath())
+					body := `{"metadata": {"name": "some-project/some-pipeline"}}`
+					if tt.getStatus != 200 {
+						body = "some error"
+					}
+
+					return &http.Response{
+						StatusCode: tt.getStatus,
+						Body:       io.NopCloser(strings.NewReader(body)),
+					}, nil
+				}
+
+				assert.Equal(t, tt.wantMethod, req.Method)
+				assert.Equal(t, tt.wantPath, req.URL.EscapedPath())
+				body, _ := io.ReadAll(req.Body)
+				assert.Contains(t, string(body), `"steps":{"clone":{"repo":"org/repo","type":"git-clone"},"build":{"image_name":"org/image","type":"build"}}`)
+				assert.NotContains(t, string(body), `"id"`)
+				assert.NotContains(t, string(body), `"created_at"`)
+				assert.NotContains(t, string(body), `"updated_at"`)
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(string(body))),
+				}, nil
+			})
+
+			p := &pipeline{
+				client: cfClient,
+			}
+			got, err := p.Apply(context.Background(), []byte(data))
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, "some-project/some-pipeline", got.Metadata.Name)
+			assert.Equal(t, []string{"clone", "build"}, stepNames(got.Spec.Steps))
+		})
+	}
+}
+
+func Test_pipeline_Modify(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		body := `{"metadata": {"name": "p0", "projectId": "some-id"}, "spec": {"variables": [{"key": "a", "value": "1"}], "concurrency": 3, "packId": "some-pack"}}`
+		if req.Method == "PUT" {
+			b, _ := io.ReadAll(req.Body)
+			body = string(b)
+		}
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}).Times(3)
+
+	p := &pipeline{
+		client: cfClient,
+	}
+	got, err := p.Modify(context.Background(), "p0", func(pipeline *Pipeline) error {
+		pipeline.Spec.Variables = append(pipeline.Spec.Variables, PipelineVariable{Key: "b", Value: "2", Encrypted: true})
+		noConcurrency := 0
+		pipeline.Spec.Concurrency = &noConcurrency
+		return nil
+	})
+	assert.NoError(t, err)
+	// an explicit 0 is sent
+	assert.Equal(t, 0, *got.Spec.Concurrency)
+	assert.Equal(t, []PipelineVariable{{Key: "a", Value: "1"}, {Key: "b", Value: "2", Encrypted: true}}, got.Spec.Variables)
+	// fields that are not modeled are sent back
+	assert.JSONEq(t, `"some-id"`, string(got.Metadata.Extra["projectId"]))
+	assert.JSONEq(t, `"some-pack"`, string(got.Spec.Extra["packId"]))
+}
+
+func Test_pipeline_Modify_Concurrent(t *testing.T) {
+	tests := []struct {
+		name      string
+		updatedAt func(get int) int
+		wantCalls int
+		wantErr   string
+	}{
+		{
+			name: "should start over when the pipeline was updated",
+			updatedAt: func(get int) int {
+				return min(get, 2)
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "should fail when the pipeline keeps being updated",
+			updatedAt: func(get int) int {
+				return get
+			},
+			wantCalls: 3,
+			wantErr:   `failed modifying pipeline: "p0" was updated while it was modified: conflict`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			gets := 0
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				body := ""
+				if req.Method == "PUT" {
+					b, _ := io.ReadAll(req.Body)
+					body = string(b)
+				} else {
+					gets++
+					body = fmt.Sprintf(`{"metadata": {"name": "p0", "updated_at": "2024-01-01T00:00:0%dZ"}}`, tt.updatedAt(gets))
+				}
+
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(body)),
+				}, nil
+			})
+
+			p := &pipeline{
+				client: cfClient,
+			}
+			calls := 0
+			got, err := p.Modify(context.Background(), "p0", func(pipeline *Pipeline) error {
+				calls++
+				pipeline.Spec.Variables = []PipelineVariable{{Key: "a", Value: "1"}}
+				return nil
+			})
+			assert.Equal(t, tt.wantCalls, calls)
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.ErrorIs(t, err, client.ErrConflict)
+				return
+			}
+
+			assert.Equal(t, []PipelineVariable{{Key: "a", Value: "1"}}, got.Spec.Variables)
+		})
+	}
+}
+
+func TestPipeline_SetWorkflow(t *testing.T) {
+	const original = `version: "1.0"
+stages:
+  - clone
+  - test
+steps:
+  main_clone:
+    type: git-clone
+    stage: clone
+    repo: org/repo
+    revision: ${{CF_BRANCH}}
+  unit_tests:
+    stage: test
+    image: golang:1.22
+    commands:
+      - go test ./...
+  parallel_checks:
+    type: parallel
+    steps:
+      lint:
+        image: golangci/golangci-lint
+        commands:
+          - golangci-lint run
+      vet:
+        image: golang:1.22
+        commands:
+          - go vet ./...
+hooks:
+  on_fail:
+    exec:
+      image: alpine
+      commands:
+        - echo failed
+`
+	p := &Pipeline{
+		Metadata: PipelineMetadata{OriginalYamlString: original},
+	}
+	w, err := p.Workflow()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0", w.Version)
+	assert.Equal(t, []string{"clone", "test"}, w.Stages)
+	assert.Equal(t, []string{"main_clone", "unit_tests", "parallel_checks"}, stepNames(w.Steps))
+	assert.JSONEq(t, `"${{CF_BRANCH}}"`, string(w.Steps[0].Extra["revision"]))
+	parallel, ok := w.Steps.Get("parallel_checks")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"lint", "vet"}, stepNames(parallel.Steps))
+
+	w.Steps = append(w.Steps, PipelineStep{Name: "deploy", Image: "alpine", Commands: []string{"echo deploy"}})
+	err = p.SetWorkflow(w)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main_clone", "unit_tests", "parallel_checks", "deploy"}, stepNames(p.Spec.Steps))
+
+	// the yaml is parsed back to the same workflow, including the fields that are not modeled
+	got, err := p.Workflow()
+	assert.NoError(t, err)
+	assert.Equal(t, w, got)
+	assert.Contains(t, p.Metadata.OriginalYamlString, "version: \"1.0\"\n")
+	assert.Contains(t, p.Metadata.OriginalYamlString, "hooks:\n  on_fail:\n")
+}
+
+func TestPipelineSteps_JSON(t *testing.T) {
+	data := `{"z_last":{"image":"alpine"},"a_first":{"type":"freestyle","fail_fast":false,"custom":{"b":1,"a":2}}}`
+	steps := PipelineSteps{}
+	err := json.Unmarshal([]byte(data), &steps)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"z_last", "a_first"}, stepNames(steps))
+	assert.False(t, *steps[1].FailFast)
+
+	got, err := json.Marshal(steps)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"z_last":{"image":"alpine"},"a_first":{"custom":{"b":1,"a":2},"fail_fast":false,"type":"freestyle"}}`, string(got))
+
+	err = json.Unmarshal([]byte(`["not", "an", "object"]`), &steps)
+	assert.EqualError(t, err, "failed unmarshaling steps: expected an object, got [")
+}
+
+func stepNames(steps PipelineSteps) []string {
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = step.Name
+	}
+
+	return names
+}
@@ -4,19 +4,30 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 
 	"github.com/codefresh-io/go-sdk/pkg/client"
 )
 
 type (
+	// Deprecated: use ProgressV2API instead
 	ProgressAPI interface {
 		Get(string) (*Progress, error)
 	}
 
+	ProgressV2API interface {
+		Get(ctx context.Context, id string) (*Progress, error)
+	}
+
 	progress struct {
 		client *client.CfClient
 	}
 
+	// progressShim implements the deprecated ProgressAPI
+	progressShim struct {
+		v2 *progress
+	}
+
 	Progress struct {
 		ID       string   `json:"id"`
 		Status   string   `json:"status"`
@@ -29,9 +40,9 @@ type (
 	}
 )
 
-func (p *progress) Get(id string) (*Progress, error) {
-	res, err := p.client.RestAPI(context.TODO(), &client.RequestOptions{
-		Path:   fmt.Sprintf("/api/progress/%s", id),
+func (p *progress) Get(ctx context.Context, id string) (*Progress, error) {
+	res, err := p.client.RestAPI(ctx, &client.RequestOptions{
+		Path:   fmt.Sprintf("/api/progress/%s", url.PathEscape(id)),
 		Method: "GET",
 	})
 	if err != nil {
@@ -41,3 +52,7 @@ func (p *progress) Get(id string) (*Progress, error) {
 	result := &Progress{}
 	return result, json.Unmarshal(res, result)
 }
+
+func (p *progressShim) Get(id string) (*Progress, error) {
+	return p.v2.Get(context.Background(), id)
+}
@@ -0,0 +1,250 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+)
+
+type (
+	ProjectAPI interface {
+		Create(ctx context.Context, opt *ProjectOptions) (*Project, error)
+		Delete(ctx context.Context, id string) error
+		Get(ctx context.Context, id string) (*Project, error)
+		GetByName(ctx context.Context, name string) (*Project, error)
+		// List returns a single page of projects, use ListPages to get all of them
+		List(ctx context.Context, opt *ProjectListOptions) ([]Project, error)
+		// ListPages calls fn with each page of projects, until there are no more pages or fn returns an error
+		ListPages(ctx context.Context, opt *ProjectListOptions, fn func(page []Project) error) error
+		// ListPipelines calls fn with each page of the pipelines in the project
+		ListPipelines(ctx context.Context, id string, opt *PipelineListOptions, fn func(page []Pipeline) error) error
+		// SetTags replaces the tags of the project
+		SetTags(ctx context.Context, id string, tags []string) (*Project, error)
+		// SetVariables replaces the variables of the project, which are available to all of its pipelines
+		SetVariables(ctx context.Context, id string, variables []PipelineVariable) (*Project, error)
+		// Update replaces the name of the project, and its tags and variables when they are not nil
+		Update(ctx context.Context, id string, opt *ProjectOptions) (*Project, error)
+	}
+
+	project struct {
+		client *client.CfClient
+	}
+
+	Project struct {
+		ID              string             `json:"id"`
+		Name            string             `json:"projectName"`
+		AccountID       string             `json:"accountId,omitempty"`
+		Tags            []string           `json:"tags"`
+		Variables       []PipelineVariable `json:"variables"`
+		Favorite        bool               `json:"favorite,omitempty"`
+		PipelinesNumber int                `json:"pipelinesNumber,omitempty"`
+		UpdatedAt       time.Time          `json:"updatedAt"`
+	}
+
+	ProjectOptions struct {
+		Name string
+		// Tags are not sent when nil, so Update keeps the current tags. An empty slice clears them
+		Tags []string
+		// Variables are not sent when nil, so Update keeps the current variables. An empty slice clears them
+		Variables []PipelineVariable
+	}
+
+	ProjectListOptions struct {
+		ListOptions
+		// Tags filters projects that have all of the tags
+		Tags []string
+	}
+
+	getProjectsResponse struct {
+		Projects []Project `json:"projects"`
+		Total    int       `json:"total"`
+	}
+)
+
+func (p *project) Create(ctx context.Context, opt *ProjectOptions) (*Project, error) {
+	if opt == nil || opt.Name == "" {
+		return nil, fmt.Errorf("failed creating project: missing name")
+	}
+
+	res, err := p.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "POST",
+		Path:   "/api/projects",
+		Body:   opt.body(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating project: %w", err)
+	}
+
+	return unmarshalProject(res)
+}
+
+func (p *project) Delete(ctx context.Context, id string) error {
+	_, err := p.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "DELETE",
+		Path:   fmt.Sprintf("/api/projects/%s", url.PathEscape(id)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed deleting project: %w", err)
+	}
+
+	return nil
+}
+
+func (p *project) Get(ctx context.Context, id string) (*Project, error) {
+	res, err := p.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "GET",
+		Path:   fmt.Sprintf("/api/projects/%s", url.PathEscape(id)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed getting project: %w", err)
+	}
+
+	return unmarshalProject(res)
+}
+
+func (p *project) GetByName(ctx context.Context, name string) (*Project, error) {
+	res, err := p.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "GET",
+		Path:   fmt.Sprintf("/api/projects/name/%s", url.PathEscape(name)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed getting project: %w", err)
+	}
+
+	return unmarshalProject(res)
+}
+
+func (p *project) List(ctx context.Context, opt *ProjectListOptions) ([]Project, error) {
+	if opt == nil {
+		opt = &ProjectListOptions{}
+	}
+
+	limit := opt.Limit
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+
+	result, err := p.list(ctx, opt, limit, opt.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Projects, nil
+}
+
+func (p *project) ListPages(ctx context.Context, opt *ProjectListOptions, fn func(page []Project) error) error {
+	if opt == nil {
+		opt = &ProjectListOptions{}
+	}
+
+	return forEachOffsetPage(ctx, &opt.ListOptions, func(ctx context.Context, limit, offset int) ([]Project, int, error) {
+		result, err := p.list(ctx, opt, limit, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return result.Projects, result.Total, nil
+	}, func(p Project) string { return p.ID }, fn)
+}
+
+func (p *project) ListPipelines(ctx context.Context, id string, opt *PipelineListOptions, fn func(page []Pipeline) error) error {
+	projectOpt := PipelineListOptions{}
+	if opt != nil {
+		projectOpt = *opt
+	}
+
+	projectOpt.ProjectID = id
+	return (&pipeline{client: p.client}).ListPages(ctx, &projectOpt, fn)
+}
+
+func (p *project) SetTags(ctx context.Context, id string, tags []string) (*Project, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return p.patch(ctx, id, map[string]any{"tags": tags})
+}
+
+func (p *project) SetVariables(ctx context.Context, id string, variables []PipelineVariable) (*Project, error) {
+	if variables == nil {
+		variables = []PipelineVariable{}
+	}
+
+	return p.patch(ctx, id, map[string]any{"variables": variables})
+}
+
+func (p *project) Update(ctx context.Context, id string, opt *ProjectOptions) (*Project, error) {
+	if opt == nil || opt.Name == "" {
+		return nil, fmt.Errorf("failed updating project: missing name")
+	}
+
+	return p.patch(ctx, id, opt.body())
+}
+
+func (p *project) list(ctx context.Context, opt *ProjectListOptions, limit, offset int) (*getProjectsResponse, error) {
+	query := pageQuery(limit, offset)
+	if len(opt.Tags) > 0 {
+		query["tags"] = opt.Tags
+	}
+
+	res, err := p.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "GET",
+		Path:   "/api/projects",
+		Query:  query,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing projects: %w", err)
+	}
+
+	result := &getProjectsResponse{}
+	err = json.Unmarshal(res, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling projects: %w", err)
+	}
+
+	return result, nil
+}
+
+// patch updates some fields of the project, and returns the updated project (the api returns no body)
+func (p *project) patch(ctx context.Context, id string, body map[string]any) (*Project, error) {
+	_, err := p.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "PATCH",
+		Path:   fmt.Sprintf("/api/projects/%s", url.PathEscape(id)),
+		Body:   body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed updating project: %w", err)
+	}
+
+	return p.Get(ctx, id)
+}
+
+// body returns the options as the api body, without the tags and variables that are nil
+func (o *ProjectOptions) body() map[string]any {
+	body := map[string]any{
+		"projectName": o.Name,
+	}
+	if o.Tags != nil {
+		body["tags"] = o.Tags
+	}
+
+	if o.Variables != nil {
+		body["variables"] = o.Variables
+	}
+
+	return body
+}
+
+func unmarshalProject(res []byte) (*Project, error) {
+	result := &Project{}
+	err := json.Unmarshal(res, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling project: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,169 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_project_ListPages(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/api/projects", req.URL.Path)
+		assert.Equal(t, []string{"team-a"}, req.URL.Query()["tags"])
+		body := `{"projects": [{"id": "1", "projectName": "a"}, {"id": "2", "projectName": "b"}], "total": 3}`
+		if req.URL.Query().Get("offset") == "2" {
+			body = `{"projects": [{"id": "3", "projectName": "c"}], "total": 3}`
+		}
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}).Times(2)
+
+	p := &project{
+		client: cfClient,
+	}
+	names := []string{}
+	err := p.ListPages(context.Background(), &ProjectListOptions{ListOptions: ListOptions{Limit: 2}, Tags: []string{"team-a"}}, func(page []Project) error {
+		for _, project := range page {
+			names = append(names, project.Name)
+		}
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+}
+
+func Test_project_updates(t *testing.T) {
+	tests := []struct {
+		name       string
+		action     func(p *project) (*Project, error)
+		wantMethod string
+		wantPath   string
+		wantBody   string
+		wantErr    string
+	}{
+		{
+			name: "should create a project",
+			action: func(p *project) (*Project, error) {
+				return p.Create(context.Background(), &ProjectOptions{Name: "some-project", Tags: []string{"team-a"}})
+			},
+			wantMethod: "POST",
+			wantPath:   "/api/projects",
+			wantBody:   `{"projectName": "some-project", "tags": ["team-a"]}`,
+		},
+		{
+			name: "should fail creating a project without a name",
+			action: func(p *project) (*Project, error) {
+				return p.Create(context.Background(), &ProjectOptions{})
+			},
+			wantErr: "failed creating project: missing name",
+		},
+		{
+			name: "should update a project without sending unset tags and variables",
+			action: func(p *project) (*Project, error) {
+				return p.Update(context.Background(), "some-id", &ProjectOptions{Name: "some-project"})
+			},
+			wantMethod: "PATCH",
+			wantPath:   "/api/projects/some-id",
+			wantBody:   `{"projectName": "some-project"}`,
+		},
+		{
+			name: "should update and clear the tags of a project",
+			action: func(p *project) (*Project, error) {
+				return p.Update(context.Background(), "some/id", &ProjectOptions{Name: "some-project", Tags: []string{}})
+			},
+			wantMethod: "PATCH",
+			wantPath:   "/api/projects/some%2Fid",
+			wantBody:   `{"projectName": "some-project", "tags": []}`,
+		},
+		{
+			name: "should set the variables of a project",
+			action: func(p *project) (*Project, error) {
+				return p.SetVariables(context.Background(), "some-id", []PipelineVariable{{Key: "A", Value: "1", Encrypted: true}})
+			},
+			wantMethod: "PATCH",
+			wantPath:   "/api/projects/some-id",
+			wantBody:   `{"variables": [{"key": "A", "value": "1", "encrypted": true}]}`,
+		},
+		{
+			name: "should clear the tags of a project",
+			action: func(p *project) (*Project, error) {
+				return p.SetTags(context.Background(), "some-id", nil)
+			},
+			wantMethod: "PATCH",
+			wantPath:   "/api/projects/some-id",
+			wantBody:   `{"tags": []}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			if tt.wantErr == "" {
+				mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					if req.Method != "GET" {
+						assert.Equal(t, tt.wantMethod, req.Method)
+						assert.Equal(t, tt.wantPath, req.URL.EscapedPath())
+						body, _ := io.ReadAll(req.Body)
+						assert.JSONEq(t, tt.wantBody, string(body))
+					}
+
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader(`{"id": "some-id", "projectName": "some-project"}`)),
+					}, nil
+				})
+			}
+
+			p := &project{
+				client: cfClient,
+			}
+			got, err := tt.action(p)
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, "some-id", got.ID)
+		})
+	}
+}
+
+func Test_project_ListPipelines(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/api/pipelines", req.URL.Path)
+		assert.Equal(t, "some-id", req.URL.Query().Get("projectId"))
+		assert.Equal(t, "web", req.URL.Query().Get("nameRegex"))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"docs": [{"metadata": {"name": "p0"}}], "count": 1}`)),
+		}, nil
+	})
+
+	p := &project{
+		client: cfClient,
+	}
+	opt := &PipelineListOptions{NameRegex: "web", ProjectID: "other-id"}
+	names := []string{}
+	err := p.ListPipelines(context.Background(), "some-id", opt, func(page []Pipeline) error {
+		for _, pipeline := range page {
+			names = append(names, pipeline.Metadata.Name)
+		}
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"p0"}, names)
+	// the options of the caller are not changed
+	assert.Equal(t, "other-id", opt.ProjectID)
+}
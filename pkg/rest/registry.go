@@ -0,0 +1,457 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+)
+
+type (
+	RegistryAPI interface {
+		// Create adds a registry integration, its kind is set from the type of the provider when empty
+		Create(ctx context.Context, registry *Registry) (*Registry, error)
+		Delete(ctx context.Context, id string) error
+		Get(ctx context.Context, id string) (*Registry, error)
+		List(ctx context.Context) ([]Registry, error)
+		// SetDefault makes the registry the default one, where builds push their images
+		SetDefault(ctx context.Context, id string) (*Registry, error)
+		// Test checks that the registry can be reached with its credentials, without saving it
+		Test(ctx context.Context, registry *Registry) error
+		// Update replaces the settings and credentials of the registry with the id. Default, Primary and BehindFirewall
+		// are only changed when they are not nil
+		Update(ctx context.Context, id string, registry *Registry) (*Registry, error)
+	}
+
+	registry struct {
+		client *client.CfClient
+	}
+
+	RegistryKind string
+
+	// Registry is a docker registry integration. The type of Provider depends on Kind:
+	//   - dockerhub: *DockerHubRegistry
+	//   - ecr: *ECRRegistry
+	//   - gcr: *GCRRegistry
+	//   - acr: *ACRRegistry
+	//   - quay: *QuayRegistry
+	//   - jfrog: *JFrogRegistry
+	//   - standard: *GenericRegistry
+	Registry struct {
+		ID   string
+		Name string
+		// Kind is set from the type of Provider when empty
+		Kind RegistryKind
+		// Default, Primary and BehindFirewall are not sent when nil, so Update keeps their current values
+		Default *bool
+		Primary *bool
+		// RepositoryPrefix is added to the names of the images that are pushed to the registry
+		RepositoryPrefix string
+		BehindFirewall   *bool
+		Provider         RegistryProvider
+	}
+
+	// RegistryProvider is the provider specific settings and credentials of a registry
+	RegistryProvider interface {
+		Kind() RegistryKind
+		validate() error
+	}
+
+	DockerHubRegistry struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	ECRRegistry struct {
+		Region          string `json:"region"`
+		AccessKeyID     string `json:"accessKeyId"`
+		SecretAccessKey string `json:"secretAccessKey"`
+	}
+
+	GCRRegistry struct {
+		// Domain is one of gcr.io, us.gcr.io, eu.gcr.io or asia.gcr.io
+		Domain string `json:"domain"`
+		// Keyfile is the json key of the service account
+		Keyfile string `json:"keyfile"`
+	}
+
+	ACRRegistry struct {
+		// Domain is the login server of the registry, e.g. myregistry.azurecr.io
+		Domain       string `json:"domain"`
+		ClientID     string `json:"clientId"`
+		ClientSecret string `json:"clientSecret"`
+	}
+
+	QuayRegistry struct {
+		Domain   string `json:"domain"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	JFrogRegistry struct {
+		Domain   string `json:"domain"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	GenericRegistry struct {
+		Domain   string `json:"domain"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	// registryFields are the fields that are common to all providers
+	registryFields struct {
+		ID               string       `json:"_id,omitempty"`
+		Name             string       `json:"name"`
+		Kind             RegistryKind `json:"provider"`
+		Default          *bool        `json:"default,omitempty"`
+		Primary          *bool        `json:"primary,omitempty"`
+		RepositoryPrefix string       `json:"repositoryPrefix,omitempty"`
+		BehindFirewall   *bool        `json:"behindFirewall,omitempty"`
+	}
+)
+
+const (
+	RegistryKindDockerHub RegistryKind = "dockerhub"
+	RegistryKindECR       RegistryKind = "ecr"
+	RegistryKindGCR       RegistryKind = "gcr"
+	RegistryKindACR       RegistryKind = "acr"
+	RegistryKindQuay      RegistryKind = "quay"
+	RegistryKindJFrog     RegistryKind = "jfrog"
+	RegistryKindGeneric   RegistryKind = "standard"
+)
+
+// secretRegistryFields are the provider fields that hold credentials, in all providers
+var secretRegistryFields = []string{"password", "secretAccessKey", "keyfile", "clientSecret"}
+
+func (*DockerHubRegistry) Kind() RegistryKind { return RegistryKindDockerHub }
+func (*ECRRegistry) Kind() RegistryKind       { return RegistryKindECR }
+func (*GCRRegistry) Kind() RegistryKind       { return RegistryKindGCR }
+func (*ACRRegistry) Kind() RegistryKind       { return RegistryKindACR }
+func (*QuayRegistry) Kind() RegistryKind      { return RegistryKindQuay }
+func (*JFrogRegistry) Kind() RegistryKind     { return RegistryKindJFrog }
+func (*GenericRegistry) Kind() RegistryKind   { return RegistryKindGeneric }
+
+func (r *registry) Create(ctx context.Context, reg *Registry) (*Registry, error) {
+	err := reg.validate()
+	if err != nil {
+		return nil, fmt.Errorf("failed creating registry: %w", err)
+	}
+
+	res, err := r.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "POST",
+		Path:   "/api/registries",
+		Body:   reg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating registry: %w", err)
+	}
+
+	return unmarshalRegistry(res)
+}
+
+func (r *registry) Delete(ctx context.Context, id string) error {
+	_, err := r.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "DELETE",
+		Path:   fmt.Sprintf("/api/registries/%s", url.PathEscape(id)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed deleting registry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *registry) Get(ctx context.Context, id string) (*Registry, error) {
+	res, err := r.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "GET",
+		Path:   fmt.Sprintf("/api/registries/%s", url.PathEscape(id)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed getting registry: %w", err)
+	}
+
+	return unmarshalRegistry(res)
+}
+
+func (r *registry) List(ctx context.Context) ([]Registry, error) {
+	res, err := r.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "GET",
+		Path:   "/api/registries",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing registries: %w", err)
+	}
+
+	result := make([]Registry, 0)
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling registries: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *registry) SetDefault(ctx context.Context, id string) (*Registry, error) {
+	res, err := r.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "PATCH",
+		Path:   fmt.Sprintf("/api/registries/%s", url.PathEscape(id)),
+		Body:   map[string]any{"default": true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed setting default registry: %w", err)
+	}
+
+	return unmarshalRegistry(res)
+}
+
+func (r *registry) Test(ctx context.Context, reg *Registry) error {
+	err := reg.validate()
+	if err != nil {
+		return fmt.Errorf("failed testing registry: %w", err)
+	}
+
+	_, err = r.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "POST",
+		Path:   "/api/registries/test",
+		Body:   reg,
+	})
+	if err != nil {
+		return fmt.Errorf("failed testing registry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *registry) Update(ctx context.Context, id string, reg *Registry) (*Registry, error) {
+	err := reg.validate()
+	if err != nil {
+		return nil, fmt.Errorf("failed updating registry: %w", err)
+	}
+
+	res, err := r.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "PATCH",
+		Path:   fmt.Sprintf("/api/registries/%s", url.PathEscape(id)),
+		Body:   reg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed updating registry: %w", err)
+	}
+
+	return unmarshalRegistry(res)
+}
+
+// MarshalJSON writes the common fields and the fields of the provider as a single object
+func (r Registry) MarshalJSON() ([]byte, error) {
+	fields := map[string]any{}
+	if r.Provider != nil {
+		data, err := json.Marshal(r.Provider)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal(data, &fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.Marshal(registryFields{
+		ID:               r.ID,
+		Name:             r.Name,
+		Kind:             r.effectiveKind(),
+		Default:          r.Default,
+		Primary:          r.Primary,
+		RepositoryPrefix: r.RepositoryPrefix,
+		BehindFirewall:   r.BehindFirewall,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(fields)
+}
+
+func (r *Registry) UnmarshalJSON(data []byte) error {
+	fields := registryFields{}
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	*r = Registry{
+		ID:               fields.ID,
+		Name:             fields.Name,
+		Kind:             fields.Kind,
+		Default:          fields.Default,
+		Primary:          fields.Primary,
+		RepositoryPrefix: fields.RepositoryPrefix,
+		BehindFirewall:   fields.BehindFirewall,
+	}
+	provider := newRegistryProvider(fields.Kind)
+	if provider == nil {
+		// an unknown provider is returned without its settings
+		return nil
+	}
+
+	err = json.Unmarshal(data, provider)
+	if err != nil {
+		return err
+	}
+
+	r.Provider = provider
+	return nil
+}
+
+// LogValue redacts the credentials of the registry
+func (r Registry) LogValue() slog.Value {
+	attrs := []slog.Attr{}
+	if r.Provider != nil {
+		fields := map[string]any{}
+		data, _ := json.Marshal(r.Provider)
+		_ = json.Unmarshal(data, &fields)
+		for _, k := range sortedKeys(fields) {
+			v := fmt.Sprint(fields[k])
+			if slices.Contains(secretRegistryFields, k) {
+				v = client.Redact(v)
+			}
+
+			attrs = append(attrs, slog.String(k, v))
+		}
+	}
+
+	return slog.GroupValue(
+		slog.String("id", r.ID),
+		slog.String("name", r.Name),
+		slog.String("kind", string(r.effectiveKind())),
+		slog.Bool("default", r.Default != nil && *r.Default),
+		slog.Attr{Key: "provider", Value: slog.GroupValue(attrs...)},
+	)
+}
+
+func (r *Registry) validate() error {
+	if r == nil || r.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+
+	if r.Provider == nil {
+		return fmt.Errorf("missing provider")
+	}
+
+	kind := r.effectiveKind()
+	if kind != r.Provider.Kind() {
+		return fmt.Errorf("kind %q does not match provider of kind %q", kind, r.Provider.Kind())
+	}
+
+	return r.Provider.validate()
+}
+
+// effectiveKind returns the kind of the registry, or the kind of its provider when it is not set
+func (r *Registry) effectiveKind() RegistryKind {
+	if r.Kind == "" && r.Provider != nil {
+		return r.Provider.Kind()
+	}
+
+	return r.Kind
+}
+
+func (p *DockerHubRegistry) validate() error {
+	if p.Username == "" {
+		return fmt.Errorf("missing username")
+	}
+
+	return nil
+}
+
+func (p *ECRRegistry) validate() error {
+	if p.Region == "" {
+		return fmt.Errorf("missing region")
+	}
+
+	return nil
+}
+
+func (p *GCRRegistry) validate() error {
+	if p.Domain == "" {
+		return fmt.Errorf("missing domain")
+	}
+
+	if p.Keyfile != "" && !json.Valid([]byte(p.Keyfile)) {
+		return fmt.Errorf("keyfile is not valid json")
+	}
+
+	return nil
+}
+
+func (p *ACRRegistry) validate() error {
+	if p.Domain == "" {
+		return fmt.Errorf("missing domain")
+	}
+
+	return nil
+}
+
+func (p *QuayRegistry) validate() error {
+	if p.Domain == "" {
+		return fmt.Errorf("missing domain")
+	}
+
+	return nil
+}
+
+func (p *JFrogRegistry) validate() error {
+	if p.Domain == "" {
+		return fmt.Errorf("missing domain")
+	}
+
+	return nil
+}
+
+func (p *GenericRegistry) validate() error {
+	if p.Domain == "" {
+		return fmt.Errorf("missing domain")
+	}
+
+	return nil
+}
+
+func newRegistryProvider(kind RegistryKind) RegistryProvider {
+	switch kind {
+	case RegistryKindDockerHub:
+		return &DockerHubRegistry{}
+	case RegistryKindECR:
+		return &ECRRegistry{}
+	case RegistryKindGCR:
+		return &GCRRegistry{}
+	case RegistryKindACR:
+		return &ACRRegistry{}
+	case RegistryKindQuay:
+		return &QuayRegistry{}
+	case RegistryKindJFrog:
+		return &JFrogRegistry{}
+	case RegistryKindGeneric:
+		return &GenericRegistry{}
+	}
+
+	return nil
+}
+
+func unmarshalRegistry(res []byte) (*Registry, error) {
+	result := &Registry{}
+	err := json.Unmarshal(res, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling registry: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,214 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_registry_Create(t *testing.T) {
+	enabled := true
+	tests := []struct {
+		name     string
+		registry *Registry
+		wantBody string
+		wantErr  string
+	}{
+		{
+			name: "should send ecr settings with the kind of the provider",
+			registry: &Registry{
+				Name:     "some-ecr",
+				Default:  &enabled,
+				Provider: &ECRRegistry{Region: "us-east-1", AccessKeyID: "key-id", SecretAccessKey: "secret-key"},
+			},
+			wantBody: `{"name": "some-ecr", "provider": "ecr", "default": true,
+				"region": "us-east-1", "accessKeyId": "key-id", "secretAccessKey": "secret-key"}`,
+		},
+		{
+			name: "should send acr settings",
+			registry: &Registry{
+				Name:             "some-acr",
+				Kind:             RegistryKindACR,
+				RepositoryPrefix: "team",
+				Provider:         &ACRRegistry{Domain: "some.azurecr.io", ClientID: "client-id", ClientSecret: "client-secret"},
+			},
+			wantBody: `{"name": "some-acr", "provider": "acr", "repositoryPrefix": "team",
+				"domain": "some.azurecr.io", "clientId": "client-id", "clientSecret": "client-secret"}`,
+		},
+		{
+			name: "should fail when the kind does not match the provider",
+			registry: &Registry{
+				Name:     "some-registry",
+				Kind:     RegistryKindQuay,
+				Provider: &DockerHubRegistry{Username: "user"},
+			},
+			wantErr: `failed creating registry: kind "quay" does not match provider of kind "dockerhub"`,
+		},
+		{
+			name: "should fail on invalid provider settings",
+			registry: &Registry{
+				Name:     "some-gcr",
+				Provider: &GCRRegistry{Domain: "gcr.io", Keyfile: "not json"},
+			},
+			wantErr: "failed creating registry: keyfile is not valid json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			if tt.wantErr == "" {
+				mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, "POST", req.Method)
+					assert.Equal(t, "/api/registries", req.URL.Path)
+					body, _ := io.ReadAll(req.Body)
+					assert.JSONEq(t, tt.wantBody, string(body))
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader(strings.Replace(string(body), "{", `{"_id": "some-id",`, 1))),
+					}, nil
+				})
+			}
+
+			r := &registry{
+				client: cfClient,
+			}
+			got, err := r.Create(context.Background(), tt.registry)
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, "some-id", got.ID)
+			assert.Equal(t, tt.registry.Provider.Kind(), got.Kind)
+			assert.Equal(t, tt.registry.Provider, got.Provider)
+		})
+	}
+}
+
+func Test_registry_List(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/api/registries", req.URL.Path)
+		return &http.Response{
+			StatusCode: 200,
+			Body: io.NopCloser(strings.NewReader(`[
+				{"_id": "1", "name": "hub", "provider": "dockerhub", "default": true, "username": "user", "password": "*****"},
+				{"_id": "2", "name": "jfrog", "provider": "jfrog", "domain": "some.jfrog.io", "username": "user"},
+				{"_id": "3", "name": "other", "provider": "some-new-provider", "domain": "some.host"}
+			]`)),
+		}, nil
+	})
+
+	r := &registry{
+		client: cfClient,
+	}
+	got, err := r.List(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, *got[0].Default)
+	assert.Nil(t, got[1].Default)
+	assert.Equal(t, &DockerHubRegistry{Username: "user", Password: "*****"}, got[0].Provider)
+	assert.Equal(t, &JFrogRegistry{Domain: "some.jfrog.io", Username: "user"}, got[1].Provider)
+	assert.Equal(t, RegistryKind("some-new-provider"), got[2].Kind)
+	assert.Nil(t, got[2].Provider)
+}
+
+func Test_registry_SetDefault(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "PATCH", req.Method)
+		assert.Equal(t, "/api/registries/some-id", req.URL.Path)
+		body, _ := io.ReadAll(req.Body)
+		assert.JSONEq(t, `{"default": true}`, string(body))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"_id": "some-id", "name": "quay", "provider": "quay", "default": true, "domain": "quay.io"}`)),
+		}, nil
+	})
+
+	r := &registry{
+		client: cfClient,
+	}
+	got, err := r.SetDefault(context.Background(), "some-id")
+	assert.NoError(t, err)
+	assert.True(t, *got.Default)
+	assert.Equal(t, &QuayRegistry{Domain: "quay.io"}, got.Provider)
+}
+
+func Test_registry_Update(t *testing.T) {
+	disabled := false
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "PATCH", req.Method)
+		assert.Equal(t, "/api/registries/some%2Fid", req.URL.EscapedPath())
+		body, _ := io.ReadAll(req.Body)
+		// the default is not sent when it is not set, and a flag that is set to false is
+		assert.JSONEq(t, `{"name": "hub", "provider": "dockerhub", "behindFirewall": false, "username": "user", "password": "new-password"}`, string(body))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"_id": "some/id", "name": "hub", "provider": "dockerhub", "default": true, "behindFirewall": false, "username": "user"}`)),
+		}, nil
+	})
+
+	r := &registry{
+		client: cfClient,
+	}
+	got, err := r.Update(context.Background(), "some/id", &Registry{
+		Name:           "hub",
+		BehindFirewall: &disabled,
+		Provider:       &DockerHubRegistry{Username: "user", Password: "new-password"},
+	})
+	assert.NoError(t, err)
+	assert.True(t, *got.Default)
+	assert.False(t, *got.BehindFirewall)
+}
+
+func Test_registry_Test(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr string
+	}{
+		{
+			name:   "should succeed when the registry is reachable",
+			status: 200,
+		},
+		{
+			name:    "should fail when the credentials are rejected",
+			status:  400,
+			wantErr: "failed testing registry: API error: : some error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, "POST", req.Method)
+				assert.Equal(t, "/api/registries/test", req.URL.Path)
+				return &http.Response{
+					StatusCode: tt.status,
+					Body:       io.NopCloser(strings.NewReader("some error")),
+				}, nil
+			})
+
+			r := &registry{
+				client: cfClient,
+			}
+			err := r.Test(context.Background(), &Registry{
+				Name:     "some-registry",
+				Provider: &GenericRegistry{Domain: "registry.host", Username: "user", Password: "pass"},
+			})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
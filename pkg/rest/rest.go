@@ -1,3 +1,9 @@
+// Package rest is the client of the classic Codefresh rest api.
+//
+// RestAPI is the original client, its methods take no context.Context and are deprecated.
+// RestV2API is the context-aware client: every method takes a context.Context as its first argument,
+// and keeps the name of the method it replaces. APIs that were added with contexts (like ProjectAPI)
+// are only part of RestV2API, and have no V2 suffix.
 package rest
 
 import "github.com/codefresh-io/go-sdk/pkg/client"
@@ -16,45 +22,69 @@ type (
 		Workflow() WorkflowAPI
 	}
 
+	RestV2API interface {
+		Argo() ArgoV2API
+		Cluster() ClusterV2API
+		Context() ContextV2API
+		Gitops() GitopsV2API
+		Pipeline() PipelineV2API
+		Progress() ProgressV2API
+		Project() ProjectAPI
+		Registry() RegistryAPI
+		RuntimeEnvironment() RuntimeEnvironmentV2API
+		Token() TokenV2API
+		User() UserAPI
+		Workflow() WorkflowV2API
+	}
+
 	restImpl struct {
 		client *client.CfClient
 	}
+
+	restV2Impl struct {
+		client *client.CfClient
+	}
 )
 
+// NewRestClient returns the original client, use NewRestV2Client for the context-aware one
 func NewRestClient(c *client.CfClient) RestAPI {
 	return &restImpl{client: c}
 }
 
+func NewRestV2Client(c *client.CfClient) RestV2API {
+	return &restV2Impl{client: c}
+}
+
 func (v1 *restImpl) Argo() ArgoAPI {
-	return &argo{client: v1.client}
+	return &argoShim{v2: &argo{client: v1.client}}
 }
 
 func (v1 *restImpl) Cluster() ClusterAPI {
-	return &cluster{client: v1.client}
+	return &clusterShim{v2: &cluster{client: v1.client}}
 }
 
 func (v1 *restImpl) Context() ContextAPI {
-	return &v1Context{client: v1.client}
+	return &contextShim{v2: v1Context{client: v1.client}}
 }
 
 func (v1 *restImpl) Gitops() GitopsAPI {
-	return &gitops{client: v1.client}
+	return &gitopsShim{v2: &gitops{client: v1.client}}
 }
 
 func (v1 *restImpl) Pipeline() PipelineAPI {
-	return &pipeline{client: v1.client}
+	return &pipelineShim{v2: &pipeline{client: v1.client}}
 }
 
 func (v1 *restImpl) Progress() ProgressAPI {
-	return &progress{client: v1.client}
+	return &progressShim{v2: &progress{client: v1.client}}
 }
 
 func (v1 *restImpl) RuntimeEnvironment() RuntimeEnvironmentAPI {
-	return &runtimeEnvironment{client: v1.client}
+	return &runtimeEnvironmentShim{v2: &runtimeEnvironment{client: v1.client}}
 }
 
 func (v1 *restImpl) Token() TokenAPI {
-	return &token{client: v1.client}
+	return &tokenShim{v2: &token{client: v1.client}}
 }
 
 func (v1 *restImpl) User() UserAPI {
@@ -62,5 +92,53 @@ func (v1 *restImpl) User() UserAPI {
 }
 
 func (v1 *restImpl) Workflow() WorkflowAPI {
-	return &workflow{codefresh: v1.client}
+	return &workflowShim{v2: &workflow{codefresh: v1.client}}
+}
+
+func (v2 *restV2Impl) Argo() ArgoV2API {
+	return &argo{client: v2.client}
+}
+
+func (v2 *restV2Impl) Cluster() ClusterV2API {
+	return &cluster{client: v2.client}
+}
+
+func (v2 *restV2Impl) Context() ContextV2API {
+	return &v1Context{client: v2.client}
+}
+
+func (v2 *restV2Impl) Gitops() GitopsV2API {
+	return &gitops{client: v2.client}
+}
+
+func (v2 *restV2Impl) Pipeline() PipelineV2API {
+	return &pipeline{client: v2.client}
+}
+
+func (v2 *restV2Impl) Progress() ProgressV2API {
+	return &progress{client: v2.client}
+}
+
+func (v2 *restV2Impl) Project() ProjectAPI {
+	return &project{client: v2.client}
+}
+
+func (v2 *restV2Impl) Registry() RegistryAPI {
+	return &registry{client: v2.client}
+}
+
+func (v2 *restV2Impl) RuntimeEnvironment() RuntimeEnvironmentV2API {
+	return &runtimeEnvironment{client: v2.client}
+}
+
+func (v2 *restV2Impl) Token() TokenV2API {
+	return &token{client: v2.client}
+}
+
+func (v2 *restV2Impl) User() UserAPI {
+	return &user{client: v2.client}
+}
+
+func (v2 *restV2Impl) Workflow() WorkflowV2API {
+	return &workflow{codefresh: v2.client}
 }
@@ -16,6 +16,8 @@ const (
 
 type (
 	// RuntimeEnvironmentAPI declers Codefresh runtime environment API
+	//
+	// Deprecated: use RuntimeEnvironmentV2API instead
 	RuntimeEnvironmentAPI interface {
 		Create(*CreateRuntimeOptions) (*RuntimeEnvironment, error)
 		Default(string) (bool, error)
@@ -26,10 +28,25 @@ type (
 		Validate(*ValidateRuntimeOptions) error
 	}
 
+	RuntimeEnvironmentV2API interface {
+		Create(ctx context.Context, opt *CreateRuntimeOptions) (*RuntimeEnvironment, error)
+		Default(ctx context.Context, name string) (bool, error)
+		Delete(ctx context.Context, name string) (bool, error)
+		Get(ctx context.Context, name string) (*RuntimeEnvironment, error)
+		List(ctx context.Context) ([]RuntimeEnvironment, error)
+		SignCertificate(ctx context.Context, opt *SignCertificatesOptions) ([]byte, error)
+		Validate(ctx context.Context, opt *ValidateRuntimeOptions) error
+	}
+
 	runtimeEnvironment struct {
 		client *client.CfClient
 	}
 
+	// runtimeEnvironmentShim implements the deprecated RuntimeEnvironmentAPI
+	runtimeEnvironmentShim struct {
+		v2 *runtimeEnvironment
+	}
+
 	RuntimeEnvironment struct {
 		Version               int                   `json:"version"`
 		Metadata              RuntimeMetadata       `json:"metadata"`
@@ -104,8 +121,8 @@ type (
 	}
 )
 
-// Create - create Runtime-Environment
-func (r *runtimeEnvironment) Create(opt *CreateRuntimeOptions) (*RuntimeEnvironment, error) {
+// Create creates a runtime environment
+func (r *runtimeEnvironment) Create(ctx context.Context, opt *CreateRuntimeOptions) (*RuntimeEnvironment, error) {
 	body := map[string]any{
 		"clusterName":        opt.Cluster,
 		"namespace":          opt.Namespace,
@@ -119,7 +136,7 @@ func (r *runtimeEnvironment) Create(opt *CreateRuntimeOptions) (*RuntimeEnvironm
 		body["agent"] = true
 	}
 
-	_, err := r.client.RestAPI(context.TODO(), &client.RequestOptions{
+	_, err := r.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "POST",
 		Path:   "/api/custom_clusters/register",
 		Body:   body,
@@ -137,8 +154,8 @@ func (r *runtimeEnvironment) Create(opt *CreateRuntimeOptions) (*RuntimeEnvironm
 	return re, nil
 }
 
-func (r *runtimeEnvironment) Default(name string) (bool, error) {
-	_, err := r.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (r *runtimeEnvironment) Default(ctx context.Context, name string) (bool, error) {
+	_, err := r.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "PUT",
 		Path:   fmt.Sprintf("/api/runtime-environments/default/%s", url.PathEscape(name)),
 	})
@@ -149,8 +166,8 @@ func (r *runtimeEnvironment) Default(name string) (bool, error) {
 	return true, nil
 }
 
-func (r *runtimeEnvironment) Delete(name string) (bool, error) {
-	_, err := r.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (r *runtimeEnvironment) Delete(ctx context.Context, name string) (bool, error) {
+	_, err := r.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/api/runtime-environments/%s", url.PathEscape(name)),
 	})
@@ -161,8 +178,8 @@ func (r *runtimeEnvironment) Delete(name string) (bool, error) {
 	return true, nil
 }
 
-func (r *runtimeEnvironment) Get(name string) (*RuntimeEnvironment, error) {
-	res, err := r.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (r *runtimeEnvironment) Get(ctx context.Context, name string) (*RuntimeEnvironment, error) {
+	res, err := r.client.RestAPI(ctx, &client.RequestOptions{
 		Method: "GET",
 		Path:   fmt.Sprintf("/api/runtime-environments/%s", url.PathEscape(name)),
 		Query: map[string]any{
@@ -177,8 +194,8 @@ func (r *runtimeEnvironment) Get(name string) (*RuntimeEnvironment, error) {
 	return result, json.Unmarshal(res, result)
 }
 
-func (r *runtimeEnvironment) List() ([]RuntimeEnvironment, error) {
-	res, err := r.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (r *runtimeEnvironment) List(ctx context.Context) ([]RuntimeEnvironment, error) {
+	res, err := r.client.RestAPI(ctx, &client.RequestOptions{
 		Path:   "/api/runtime-environments",
 		Method: "GET",
 	})
@@ -190,8 +207,8 @@ func (r *runtimeEnvironment) List() ([]RuntimeEnvironment, error) {
 	return result, json.Unmarshal(res, &result)
 }
 
-func (r *runtimeEnvironment) SignCertificate(opt *SignCertificatesOptions) ([]byte, error) {
-	res, err := r.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (r *runtimeEnvironment) SignCertificate(ctx context.Context, opt *SignCertificatesOptions) ([]byte, error) {
+	res, err := r.client.RestAPI(ctx, &client.RequestOptions{
 		Path:   "/api/custom_clusters/signServerCerts",
 		Method: "POST",
 		Body: map[string]any{
@@ -206,8 +223,8 @@ func (r *runtimeEnvironment) SignCertificate(opt *SignCertificatesOptions) ([]by
 	return res, err
 }
 
-func (r *runtimeEnvironment) Validate(opt *ValidateRuntimeOptions) error {
-	_, err := r.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (r *runtimeEnvironment) Validate(ctx context.Context, opt *ValidateRuntimeOptions) error {
+	_, err := r.client.RestAPI(ctx, &client.RequestOptions{
 		Path:   "/api/custom_clusters/validate",
 		Method: "POST",
 		Body: map[string]any{
@@ -221,3 +238,31 @@ func (r *runtimeEnvironment) Validate(opt *ValidateRuntimeOptions) error {
 
 	return nil
 }
+
+func (r *runtimeEnvironmentShim) Create(opt *CreateRuntimeOptions) (*RuntimeEnvironment, error) {
+	return r.v2.Create(context.Background(), opt)
+}
+
+func (r *runtimeEnvironmentShim) Default(name string) (bool, error) {
+	return r.v2.Default(context.Background(), name)
+}
+
+func (r *runtimeEnvironmentShim) Delete(name string) (bool, error) {
+	return r.v2.Delete(context.Background(), name)
+}
+
+func (r *runtimeEnvironmentShim) Get(name string) (*RuntimeEnvironment, error) {
+	return r.v2.Get(context.Background(), name)
+}
+
+func (r *runtimeEnvironmentShim) List() ([]RuntimeEnvironment, error) {
+	return r.v2.List(context.Background())
+}
+
+func (r *runtimeEnvironmentShim) SignCertificate(opt *SignCertificatesOptions) ([]byte, error) {
+	return r.v2.SignCertificate(context.Background(), opt)
+}
+
+func (r *runtimeEnvironmentShim) Validate(opt *ValidateRuntimeOptions) error {
+	return r.v2.Validate(context.Background(), opt)
+}
@@ -0,0 +1,357 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+)
+
+type (
+	ContextType string
+
+	// SharedContext is a context of any type. The type of Spec.Data depends on Spec.Type:
+	//   - config, secret: ConfigData
+	//   - yaml, secret-yaml: YamlData
+	//   - helm-repository: HelmRepositoryData
+	//   - storage.*: StorageData
+	//   - other types: map[string]any
+	SharedContext struct {
+		Metadata ContextMetadata   `json:"metadata"`
+		Spec     SharedContextSpec `json:"spec"`
+	}
+
+	ContextMetadata struct {
+		Name      string `json:"name"`
+		AccountID string `json:"account,omitempty"`
+	}
+
+	SharedContextSpec struct {
+		Type ContextType `json:"type"`
+		Data any         `json:"data"`
+	}
+
+	// ConfigData holds the key-value pairs of config and secret contexts
+	ConfigData map[string]string
+
+	// YamlData holds the document of yaml and secret-yaml contexts
+	YamlData map[string]any
+
+	HelmRepositoryData struct {
+		// Variables are the repository settings, e.g. HELMREPO_URL, HELMREPO_USERNAME and HELMREPO_PASSWORD
+		Variables map[string]string `json:"variables"`
+	}
+
+	StorageData struct {
+		Auth StorageAuth `json:"auth"`
+	}
+
+	StorageAuth struct {
+		Type string `json:"type"`
+		// JSONKeyfile is the service account key of google cloud storage
+		JSONKeyfile     map[string]any `json:"jsonKeyfile,omitempty"`
+		AccessKeyID     string         `json:"accessKeyId,omitempty"`
+		SecretAccessKey string         `json:"secretAccessKey,omitempty"`
+		AccountName     string         `json:"accountName,omitempty"`
+		AccountKey      string         `json:"accountKey,omitempty"`
+	}
+
+	ContextGetOptions struct {
+		// Decrypt returns the secret values in plain text, they are masked otherwise
+		Decrypt bool
+	}
+
+	ContextListOptions struct {
+		// Types filters contexts of one of these types (default: all types)
+		Types []ContextType
+		// Decrypt returns the secret values in plain text, they are masked otherwise
+		Decrypt bool
+	}
+)
+
+const (
+	ContextTypeConfig         ContextType = "config"
+	ContextTypeSecret         ContextType = "secret"
+	ContextTypeYaml           ContextType = "yaml"
+	ContextTypeSecretYaml     ContextType = "secret-yaml"
+	ContextTypeHelmRepository ContextType = "helm-repository"
+	ContextTypeStorageGC      ContextType = "storage.gc"
+	ContextTypeStorageS3      ContextType = "storage.s3"
+	ContextTypeStorageAzure   ContextType = "storage.azuref"
+)
+
+// secretHelmVariables are the helm repository variables that hold credentials
+var secretHelmVariables = []string{"HELMREPO_PASSWORD", "AWS_SECRET_ACCESS_KEY", "GOOGLE_APPLICATION_CREDENTIALS_JSON", "AZURE_CLIENT_SECRET"}
+
+func (c v1Context) Create(ctx context.Context, sharedContext *SharedContext) (*SharedContext, error) {
+	err := sharedContext.validate()
+	if err != nil {
+		return nil, fmt.Errorf("failed creating context: %w", err)
+	}
+
+	res, err := c.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "POST",
+		Path:   "/api/contexts",
+		Body:   sharedContext.body(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating context: %w", err)
+	}
+
+	return unmarshalSharedContext(res)
+}
+
+func (c v1Context) Delete(ctx context.Context, name string) error {
+	_, err := c.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "DELETE",
+		Path:   fmt.Sprintf("/api/contexts/%s", url.PathEscape(name)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed deleting context: %w", err)
+	}
+
+	return nil
+}
+
+func (c v1Context) Get(ctx context.Context, name string, opt *ContextGetOptions) (*SharedContext, error) {
+	if opt == nil {
+		opt = &ContextGetOptions{}
+	}
+
+	res, err := c.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "GET",
+		Path:   fmt.Sprintf("/api/contexts/%s", url.PathEscape(name)),
+		Query:  decryptQuery(opt.Decrypt),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed getting context: %w", err)
+	}
+
+	return unmarshalSharedContext(res)
+}
+
+func (c v1Context) List(ctx context.Context, opt *ContextListOptions) ([]SharedContext, error) {
+	if opt == nil {
+		opt = &ContextListOptions{}
+	}
+
+	query := decryptQuery(opt.Decrypt)
+	if len(opt.Types) > 0 {
+		query["type"] = contextTypeStrings(opt.Types)
+	}
+
+	res, err := c.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "GET",
+		Path:   "/api/contexts",
+		Query:  query,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing contexts: %w", err)
+	}
+
+	result := make([]SharedContext, 0)
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling contexts: %w", err)
+	}
+
+	return result, nil
+}
+
+func (c v1Context) Update(ctx context.Context, sharedContext *SharedContext) (*SharedContext, error) {
+	err := sharedContext.validate()
+	if err != nil {
+		return nil, fmt.Errorf("failed updating context: %w", err)
+	}
+
+	res, err := c.client.RestAPI(ctx, &client.RequestOptions{
+		Method: "PUT",
+		Path:   fmt.Sprintf("/api/contexts/%s", url.PathEscape(sharedContext.Metadata.Name)),
+		Body:   sharedContext.body(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed updating context: %w", err)
+	}
+
+	return unmarshalSharedContext(res)
+}
+
+// IsSecret returns true for the types whose values are masked unless decrypted
+func (t ContextType) IsSecret() bool {
+	return t == ContextTypeSecret || t == ContextTypeSecretYaml || t == ContextTypeHelmRepository || t.IsStorage()
+}
+
+func (t ContextType) IsStorage() bool {
+	return strings.HasPrefix(string(t), "storage.")
+}
+
+func (s *SharedContextSpec) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Type ContextType     `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}{}
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	s.Type = raw.Type
+	s.Data, err = unmarshalContextData(raw.Type, raw.Data)
+	return err
+}
+
+// LogValue redacts the values of secret contexts, and the credentials of the other types
+func (c SharedContext) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("name", c.Metadata.Name),
+		slog.String("type", string(c.Spec.Type)),
+		slog.Any("data", c.Spec.dataLogValue()),
+	)
+}
+
+func (s SharedContextSpec) dataLogValue() slog.Value {
+	switch data := s.Data.(type) {
+	case ConfigData:
+		attrs := make([]slog.Attr, 0, len(data))
+		for _, k := range sortedKeys(data) {
+			v := data[k]
+			if s.Type.IsSecret() {
+				v = client.Redact(v)
+			}
+
+			attrs = append(attrs, slog.String(k, v))
+		}
+
+		return slog.GroupValue(attrs...)
+	case HelmRepositoryData:
+		attrs := make([]slog.Attr, 0, len(data.Variables))
+		for _, k := range sortedKeys(data.Variables) {
+			v := data.Variables[k]
+			if slices.Contains(secretHelmVariables, k) {
+				v = client.Redact(v)
+			}
+
+			attrs = append(attrs, slog.String(k, v))
+		}
+
+		return slog.GroupValue(slog.Attr{Key: "variables", Value: slog.GroupValue(attrs...)})
+	case StorageData:
+		keyfile := ""
+		if len(data.Auth.JSONKeyfile) > 0 {
+			keyfile = client.Redacted
+		}
+
+		return slog.GroupValue(slog.Group("auth",
+			slog.String("type", data.Auth.Type),
+			slog.String("jsonKeyfile", keyfile),
+			slog.String("accessKeyId", data.Auth.AccessKeyID),
+			slog.String("secretAccessKey", client.Redact(data.Auth.SecretAccessKey)),
+			slog.String("accountName", data.Auth.AccountName),
+			slog.String("accountKey", client.Redact(data.Auth.AccountKey)),
+		))
+	case nil:
+		return slog.Value{}
+	}
+
+	if s.Type == ContextTypeYaml {
+		return slog.AnyValue(s.Data)
+	}
+
+	// the structure of other types is unknown, so none of it is logged
+	return slog.StringValue(client.Redacted)
+}
+
+func (c *SharedContext) validate() error {
+	if c == nil || c.Metadata.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+
+	if c.Spec.Type == "" {
+		return fmt.Errorf("missing type")
+	}
+
+	return nil
+}
+
+func (c *SharedContext) body() map[string]any {
+	data := c.Spec.Data
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "context",
+		"metadata": map[string]any{
+			"name": c.Metadata.Name,
+		},
+		"spec": map[string]any{
+			"type": c.Spec.Type,
+			"data": data,
+		},
+	}
+}
+
+func decryptQuery(decrypt bool) map[string]any {
+	if !decrypt {
+		return map[string]any{}
+	}
+
+	return map[string]any{"decrypt": "true"}
+}
+
+func unmarshalContextData(t ContextType, data json.RawMessage) (any, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	var err error
+	switch {
+	case t == ContextTypeConfig || t == ContextTypeSecret:
+		result := ConfigData{}
+		err = json.Unmarshal(data, &result)
+		return result, err
+	case t == ContextTypeYaml || t == ContextTypeSecretYaml:
+		result := YamlData{}
+		err = json.Unmarshal(data, &result)
+		return result, err
+	case t == ContextTypeHelmRepository:
+		result := HelmRepositoryData{}
+		err = json.Unmarshal(data, &result)
+		return result, err
+	case t.IsStorage():
+		result := StorageData{}
+		err = json.Unmarshal(data, &result)
+		return result, err
+	}
+
+	result := map[string]any{}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
+
+func unmarshalSharedContext(res []byte) (*SharedContext, error) {
+	result := &SharedContext{}
+	err := json.Unmarshal(res, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling context: %w", err)
+	}
+
+	return result, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
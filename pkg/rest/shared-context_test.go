@@ -0,0 +1,144 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_v1Context_List(t *testing.T) {
+	tests := []struct {
+		name      string
+		opt       *ContextListOptions
+		wantQuery string
+	}{
+		{
+			name:      "should list all types masked by default",
+			wantQuery: "",
+		},
+		{
+			name:      "should filter by type and decrypt when asked",
+			opt:       &ContextListOptions{Types: []ContextType{ContextTypeSecret, ContextTypeHelmRepository}, Decrypt: true},
+			wantQuery: "decrypt=true&type=secret&type=helm-repository",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, "/api/contexts", req.URL.Path)
+				assert.Equal(t, tt.wantQuery, req.URL.RawQuery)
+				return &http.Response{
+					StatusCode: 200,
+					Body: io.NopCloser(strings.NewReader(`[
+						{"metadata": {"name": "config"}, "spec": {"type": "config", "data": {"A": "1"}}},
+						{"metadata": {"name": "secret"}, "spec": {"type": "secret", "data": {"B": "*****"}}},
+						{"metadata": {"name": "yaml"}, "spec": {"type": "secret-yaml", "data": {"nested": {"key": "value"}}}},
+						{"metadata": {"name": "helm"}, "spec": {"type": "helm-repository", "data": {"variables": {"HELMREPO_URL": "https://charts.host"}}}},
+						{"metadata": {"name": "gcs"}, "spec": {"type": "storage.gc", "data": {"auth": {"type": "basic", "jsonKeyfile": {"type": "service_account"}}}}},
+						{"metadata": {"name": "other"}, "spec": {"type": "git.github", "data": {"auth": {"type": "basic"}}}}
+					]`)),
+				}, nil
+			})
+
+			c := v1Context{
+				client: cfClient,
+			}
+			got, err := c.List(context.Background(), tt.opt)
+			assert.NoError(t, err)
+			assert.Equal(t, ConfigData{"A": "1"}, got[0].Spec.Data)
+			assert.Equal(t, ConfigData{"B": "*****"}, got[1].Spec.Data)
+			assert.Equal(t, YamlData{"nested": map[string]any{"key": "value"}}, got[2].Spec.Data)
+			assert.Equal(t, HelmRepositoryData{Variables: map[string]string{"HELMREPO_URL": "https://charts.host"}}, got[3].Spec.Data)
+			assert.Equal(t, StorageData{Auth: StorageAuth{Type: "basic", JSONKeyfile: map[string]any{"type": "service_account"}}}, got[4].Spec.Data)
+			assert.Equal(t, map[string]any{"auth": map[string]any{"type": "basic"}}, got[5].Spec.Data)
+		})
+	}
+}
+
+func Test_v1Context_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		sharedContext *SharedContext
+		wantBody      string
+		wantErr       string
+	}{
+		{
+			name: "should send the context",
+			sharedContext: &SharedContext{
+				Metadata: ContextMetadata{Name: "some-secret"},
+				Spec:     SharedContextSpec{Type: ContextTypeSecret, Data: ConfigData{"TOKEN": "value"}},
+			},
+			wantBody: `{"apiVersion": "v1", "kind": "context", "metadata": {"name": "some-secret"}, "spec": {"type": "secret", "data": {"TOKEN": "value"}}}`,
+		},
+		{
+			name: "should send empty data",
+			sharedContext: &SharedContext{
+				Metadata: ContextMetadata{Name: "some-config"},
+				Spec:     SharedContextSpec{Type: ContextTypeConfig},
+			},
+			wantBody: `{"apiVersion": "v1", "kind": "context", "metadata": {"name": "some-config"}, "spec": {"type": "config", "data": {}}}`,
+		},
+		{
+			name: "should fail without type",
+			sharedContext: &SharedContext{
+				Metadata: ContextMetadata{Name: "some-config"},
+			},
+			wantErr: "failed creating context: missing type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			if tt.wantErr == "" {
+				mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, "POST", req.Method)
+					assert.Equal(t, "/api/contexts", req.URL.Path)
+					body, _ := io.ReadAll(req.Body)
+					assert.JSONEq(t, tt.wantBody, string(body))
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader(string(body))),
+					}, nil
+				})
+			}
+
+			c := v1Context{
+				client: cfClient,
+			}
+			got, err := c.Create(context.Background(), tt.sharedContext)
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.sharedContext.Metadata.Name, got.Metadata.Name)
+			assert.Equal(t, tt.sharedContext.Spec.Type, got.Spec.Type)
+		})
+	}
+}
+
+func Test_v1Context_Get(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/api/contexts/some-secret", req.URL.Path)
+		assert.Equal(t, "", req.URL.RawQuery)
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"metadata": {"name": "some-secret"}, "spec": {"type": "secret", "data": {"TOKEN": "*****"}}}`)),
+		}, nil
+	})
+
+	c := v1Context{
+		client: cfClient,
+	}
+	got, err := c.Get(context.Background(), "some-secret", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ConfigData{"TOKEN": "*****"}, got.Spec.Data)
+}
@@ -4,21 +4,46 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net/url"
 	"time"
 
 	"github.com/codefresh-io/go-sdk/pkg/client"
 )
 
 type (
+	// Deprecated: use TokenV2API instead
 	TokenAPI interface {
 		Create(name string, subject string) (*Token, error)
 		List() ([]Token, error)
 	}
 
+	TokenV2API interface {
+		// Create creates a token with the subject, scopes and expiry of the options
+		Create(ctx context.Context, opt *TokenCreateOptions) (*Token, error)
+		// Delete removes the token, it can not be used after it is deleted
+		Delete(ctx context.Context, id string) error
+		Get(ctx context.Context, id string) (*Token, error)
+		// List returns a single page of tokens, use ListPages to get all of them
+		List(ctx context.Context, opt *TokenListOptions) ([]Token, error)
+		// ListPages calls fn with each page of tokens, until there are no more pages or fn returns an error
+		ListPages(ctx context.Context, opt *TokenListOptions, fn func(page []Token) error) error
+		// Revoke invalidates the token, it is kept in the list of tokens as revoked
+		Revoke(ctx context.Context, id string) error
+		// Rotate creates a replacement token with the same subject and scopes, and then revokes the old one.
+		// When revoking fails, the new token is returned with the error, so it is not lost
+		Rotate(ctx context.Context, id string, opt *TokenRotateOptions) (*Token, error)
+	}
+
 	token struct {
 		client *client.CfClient
 	}
 
+	// tokenShim implements the deprecated TokenAPI
+	tokenShim struct {
+		v2 *token
+	}
+
 	Token struct {
 		ID          string    `json:"_id"`
 		Name        string    `json:"name"`
@@ -28,22 +53,234 @@ type (
 			Type string `json:"type"`
 			Ref  string `json:"ref"`
 		} `json:"subject"`
-		Value string
+		Scopes []string `json:"scopes"`
+		// ExpiresAt is the zero time for a token that does not expire
+		ExpiresAt time.Time `json:"expiresAt"`
+		Revoked   bool      `json:"revoked"`
+		Value     string
+	}
+
+	TokenCreateOptions struct {
+		Name        string
+		SubjectType TokenSubjectType
+		// SubjectRef is the name of the runtime environment, or the id of the user or account (default: the current one)
+		SubjectRef string
+		// Scopes are the permissions of the token, e.g. "pipeline" or "pipeline:read" (default: all scopes of the subject)
+		Scopes []string
+		// ExpiresAt is the time the token expires (default: never)
+		ExpiresAt time.Time
+	}
+
+	TokenListOptions struct {
+		ListOptions
+		SubjectType TokenSubjectType
+		SubjectRef  string
+	}
+
+	TokenRotateOptions struct {
+		// Name is the name of the new token (default: the name of the old token)
+		Name string
+		// ExpiresAt is the time the new token expires (default: the lifetime of the old token, starting now)
+		ExpiresAt time.Time
 	}
 
-	tokenSubjectType int
+	TokenSubjectType string
 )
 
 const (
-	runtimeEnvironmentSubject tokenSubjectType = 0
+	TokenSubjectRuntimeEnvironment TokenSubjectType = "runtime-environment"
+	TokenSubjectUser               TokenSubjectType = "user"
+	TokenSubjectAccount            TokenSubjectType = "account"
 )
 
-func (s tokenSubjectType) String() string {
-	return [...]string{"runtime-environment"}[s]
+func (t *token) Create(ctx context.Context, opt *TokenCreateOptions) (*Token, error) {
+	err := opt.validate()
+	if err != nil {
+		return nil, fmt.Errorf("failed creating token: %w", err)
+	}
+
+	body := map[string]any{
+		"name": opt.Name,
+	}
+	if len(opt.Scopes) > 0 {
+		body["scopes"] = opt.Scopes
+	}
+
+	if !opt.ExpiresAt.IsZero() {
+		body["expiresAt"] = opt.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	query := map[string]any{
+		"subjectType": string(opt.SubjectType),
+	}
+	if opt.SubjectRef != "" {
+		query["subjectReference"] = opt.SubjectRef
+	}
+
+	res, err := t.client.RestAPI(ctx, &client.RequestOptions{
+		Path:   "/api/auth/key",
+		Method: "POST",
+		Body:   body,
+		Query:  query,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating token: %w", err)
+	}
+
+	result := &Token{
+		Name:      opt.Name,
+		Scopes:    opt.Scopes,
+		ExpiresAt: opt.ExpiresAt,
+		Value:     string(res),
+	}
+	result.Subject.Type = string(opt.SubjectType)
+	result.Subject.Ref = opt.SubjectRef
+	return result, nil
+}
+
+func (t *token) Delete(ctx context.Context, id string) error {
+	_, err := t.client.RestAPI(ctx, &client.RequestOptions{
+		Path:   fmt.Sprintf("/api/auth/key/%s", url.PathEscape(id)),
+		Method: "DELETE",
+	})
+	if err != nil {
+		return fmt.Errorf("failed deleting token: %w", err)
+	}
+
+	return nil
+}
+
+func (t *token) Get(ctx context.Context, id string) (*Token, error) {
+	res, err := t.client.RestAPI(ctx, &client.RequestOptions{
+		Path:   fmt.Sprintf("/api/auth/key/%s", url.PathEscape(id)),
+		Method: "GET",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed getting token: %w", err)
+	}
+
+	result := &Token{}
+	err = json.Unmarshal(res, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling token: %w", err)
+	}
+
+	return result, nil
+}
+
+func (t *token) List(ctx context.Context, opt *TokenListOptions) ([]Token, error) {
+	if opt == nil {
+		opt = &TokenListOptions{}
+	}
+
+	limit := opt.Limit
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+
+	return t.list(ctx, opt, limit, opt.Offset)
 }
 
-func (t *token) Create(name string, subject string) (*Token, error) {
-	res, err := t.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (t *token) ListPages(ctx context.Context, opt *TokenListOptions, fn func(page []Token) error) error {
+	if opt == nil {
+		opt = &TokenListOptions{}
+	}
+
+	return forEachOffsetPage(ctx, &opt.ListOptions, func(ctx context.Context, limit, offset int) ([]Token, int, error) {
+		result, err := t.list(ctx, opt, limit, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return result, -1, nil
+	}, func(t Token) string { return t.ID }, fn)
+}
+
+func (t *token) Revoke(ctx context.Context, id string) error {
+	_, err := t.client.RestAPI(ctx, &client.RequestOptions{
+		Path:   fmt.Sprintf("/api/auth/key/%s/revoke", url.PathEscape(id)),
+		Method: "POST",
+	})
+	if err != nil {
+		return fmt.Errorf("failed revoking token: %w", err)
+	}
+
+	return nil
+}
+
+func (t *token) Rotate(ctx context.Context, id string, opt *TokenRotateOptions) (*Token, error) {
+	if opt == nil {
+		opt = &TokenRotateOptions{}
+	}
+
+	old, err := t.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed rotating token: %w", err)
+	}
+
+	createOpt := &TokenCreateOptions{
+		Name:        opt.Name,
+		SubjectType: TokenSubjectType(old.Subject.Type),
+		SubjectRef:  old.Subject.Ref,
+		Scopes:      old.Scopes,
+		ExpiresAt:   opt.ExpiresAt,
+	}
+	if createOpt.Name == "" {
+		createOpt.Name = old.Name
+	}
+
+	if createOpt.ExpiresAt.IsZero() && !old.ExpiresAt.IsZero() && !old.Created.IsZero() {
+		createOpt.ExpiresAt = time.Now().Add(old.ExpiresAt.Sub(old.Created))
+	}
+
+	result, err := t.Create(ctx, createOpt)
+	if err != nil {
+		return nil, fmt.Errorf("failed rotating token: %w", err)
+	}
+
+	err = t.Revoke(ctx, old.ID)
+	if err != nil {
+		return result, fmt.Errorf("failed rotating token, the new token was created: %w", err)
+	}
+
+	return result, nil
+}
+
+// IsExpired returns true when the token has an expiry time, and it has passed
+func (t Token) IsExpired() bool {
+	return !t.ExpiresAt.IsZero() && !time.Now().Before(t.ExpiresAt)
+}
+
+func (t *token) list(ctx context.Context, opt *TokenListOptions, limit, offset int) ([]Token, error) {
+	query := pageQuery(limit, offset)
+	if opt.SubjectType != "" {
+		query["subjectType"] = string(opt.SubjectType)
+	}
+
+	if opt.SubjectRef != "" {
+		query["subjectReference"] = opt.SubjectRef
+	}
+
+	res, err := t.client.RestAPI(ctx, &client.RequestOptions{
+		Path:   "/api/auth/keys",
+		Method: "GET",
+		Query:  query,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing tokens: %w", err)
+	}
+
+	result := make([]Token, 0)
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling tokens: %w", err)
+	}
+
+	return result, nil
+}
+
+func (t *tokenShim) Create(name string, subject string) (*Token, error) {
+	res, err := t.v2.client.RestAPI(context.Background(), &client.RequestOptions{
 		Path:   "/api/auth/key",
 		Method: "POST",
 		Body: map[string]any{
@@ -51,7 +288,7 @@ func (t *token) Create(name string, subject string) (*Token, error) {
 		},
 		Query: map[string]any{
 			"subjectReference": subject,
-			"subjectType":      runtimeEnvironmentSubject.String(),
+			"subjectType":      string(TokenSubjectRuntimeEnvironment),
 		},
 	})
 	if err != nil {
@@ -64,8 +301,8 @@ func (t *token) Create(name string, subject string) (*Token, error) {
 	}, err
 }
 
-func (t *token) List() ([]Token, error) {
-	res, err := t.client.RestAPI(context.TODO(), &client.RequestOptions{
+func (t *tokenShim) List() ([]Token, error) {
+	res, err := t.v2.client.RestAPI(context.Background(), &client.RequestOptions{
 		Path:   "/api/auth/keys",
 		Method: "GET",
 	})
@@ -76,3 +313,47 @@ func (t *token) List() ([]Token, error) {
 	result := make([]Token, 0)
 	return result, json.Unmarshal(res, &result)
 }
+
+func (o *TokenCreateOptions) validate() error {
+	if o == nil || o.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+
+	switch o.SubjectType {
+	case TokenSubjectUser, TokenSubjectAccount:
+	case TokenSubjectRuntimeEnvironment:
+		if o.SubjectRef == "" {
+			return fmt.Errorf("missing runtime environment name")
+		}
+	case "":
+		return fmt.Errorf("missing subject type")
+	default:
+		return fmt.Errorf("unknown subject type %q", o.SubjectType)
+	}
+
+	for _, scope := range o.Scopes {
+		if scope == "" {
+			return fmt.Errorf("empty scope")
+		}
+	}
+
+	return nil
+}
+
+// LogValue redacts the value of the token
+func (t Token) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", t.ID),
+		slog.String("name", t.Name),
+		slog.String("tokenPrefix", t.TokenPrefix),
+		slog.Time("created", t.Created),
+		slog.Time("expiresAt", t.ExpiresAt),
+		slog.Any("scopes", t.Scopes),
+		slog.Bool("revoked", t.Revoked),
+		slog.Group("subject",
+			slog.String("type", t.Subject.Type),
+			slog.String("ref", t.Subject.Ref),
+		),
+		slog.String("value", client.Redact(t.Value)),
+	)
+}
@@ -0,0 +1,184 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_token_Create(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name      string
+		opt       *TokenCreateOptions
+		wantQuery string
+		wantBody  string
+		wantErr   string
+	}{
+		{
+			name:      "should create a user token with scopes and expiry",
+			opt:       &TokenCreateOptions{Name: "some-token", SubjectType: TokenSubjectUser, Scopes: []string{"pipeline", "project:read"}, ExpiresAt: expiresAt},
+			wantQuery: "subjectType=user",
+			wantBody:  `{"name": "some-token", "scopes": ["pipeline", "project:read"], "expiresAt": "2030-01-02T03:04:05Z"}`,
+		},
+		{
+			name:      "should create a runtime environment token",
+			opt:       &TokenCreateOptions{Name: "some-token", SubjectType: TokenSubjectRuntimeEnvironment, SubjectRef: "some-re"},
+			wantQuery: "subjectReference=some-re&subjectType=runtime-environment",
+			wantBody:  `{"name": "some-token"}`,
+		},
+		{
+			name:    "should fail on a runtime environment token without a runtime",
+			opt:     &TokenCreateOptions{Name: "some-token", SubjectType: TokenSubjectRuntimeEnvironment},
+			wantErr: "failed creating token: missing runtime environment name",
+		},
+		{
+			name:    "should fail on an unknown subject type",
+			opt:     &TokenCreateOptions{Name: "some-token", SubjectType: "team"},
+			wantErr: `failed creating token: unknown subject type "team"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			if tt.wantErr == "" {
+				mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, "POST", req.Method)
+					assert.Equal(t, "/api/auth/key", req.URL.Path)
+					assert.Equal(t, tt.wantQuery, req.URL.RawQuery)
+					body, _ := io.ReadAll(req.Body)
+					assert.JSONEq(t, tt.wantBody, string(body))
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader("token-value")),
+					}, nil
+				})
+			}
+
+			tok := &token{
+				client: cfClient,
+			}
+			got, err := tok.Create(context.Background(), tt.opt)
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, "token-value", got.Value)
+			assert.Equal(t, tt.opt.Scopes, got.Scopes)
+			assert.Equal(t, tt.opt.ExpiresAt, got.ExpiresAt)
+			assert.Equal(t, string(tt.opt.SubjectType), got.Subject.Type)
+		})
+	}
+}
+
+func Test_token_List(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/api/auth/keys", req.URL.Path)
+		assert.Equal(t, "limit=100&offset=0&subjectReference=some-re&subjectType=runtime-environment", req.URL.RawQuery)
+		return &http.Response{
+			StatusCode: 200,
+			Body: io.NopCloser(strings.NewReader(`[
+				{"_id": "1", "name": "old", "scopes": ["pipeline"], "expiresAt": "2000-01-01T00:00:00Z", "subject": {"type": "runtime-environment", "ref": "some-re"}},
+				{"_id": "2", "name": "new", "scopes": ["pipeline"], "subject": {"type": "runtime-environment", "ref": "some-re"}}
+			]`)),
+		}, nil
+	})
+
+	tok := &token{
+		client: cfClient,
+	}
+	got, err := tok.List(context.Background(), &TokenListOptions{SubjectType: TokenSubjectRuntimeEnvironment, SubjectRef: "some-re"})
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.True(t, got[0].IsExpired())
+	assert.False(t, got[1].IsExpired())
+}
+
+func Test_token_Rotate(t *testing.T) {
+	tests := []struct {
+		name          string
+		revokeStatus  int
+		wantErr       string
+		wantNewToken  bool
+		wantExpiresIn time.Duration
+	}{
+		{
+			name:          "should create a new token with the same scopes and lifetime, and revoke the old one",
+			revokeStatus:  200,
+			wantNewToken:  true,
+			wantExpiresIn: 30 * 24 * time.Hour,
+		},
+		{
+			name:         "should return the new token when revoking fails",
+			revokeStatus: 500,
+			wantErr:      "failed rotating token, the new token was created: failed revoking token: API error: : some error",
+			wantNewToken: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			calls := []string{}
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, req.Method+" "+req.URL.Path)
+				switch req.Method + " " + req.URL.Path {
+				case "GET /api/auth/key/old-id":
+					return &http.Response{
+						StatusCode: 200,
+						Body: io.NopCloser(strings.NewReader(`{
+							"_id": "old-id", "name": "ci", "scopes": ["pipeline", "project:read"],
+							"created": "2024-01-01T00:00:00Z", "expiresAt": "2024-01-31T00:00:00Z",
+							"subject": {"type": "user", "ref": "some-user"}
+						}`)),
+					}, nil
+				case "POST /api/auth/key":
+					assert.Equal(t, "subjectReference=some-user&subjectType=user", req.URL.RawQuery)
+					body, _ := io.ReadAll(req.Body)
+					assert.Contains(t, string(body), `"scopes":["pipeline","project:read"]`)
+					assert.Contains(t, string(body), `"name":"ci"`)
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader("new-value")),
+					}, nil
+				case "POST /api/auth/key/old-id/revoke":
+					return &http.Response{
+						StatusCode: tt.revokeStatus,
+						Body:       io.NopCloser(strings.NewReader("some error")),
+					}, nil
+				}
+
+				return nil, errors.New("unexpected request " + req.URL.Path)
+			})
+
+			tok := &token{
+				client: cfClient,
+			}
+			got, err := tok.Rotate(context.Background(), "old-id", nil)
+			assert.Equal(t, []string{"GET /api/auth/key/old-id", "POST /api/auth/key", "POST /api/auth/key/old-id/revoke"}, calls)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tt.wantNewToken {
+				assert.Equal(t, "new-value", got.Value)
+				assert.Equal(t, []string{"pipeline", "project:read"}, got.Scopes)
+			}
+
+			if tt.wantExpiresIn > 0 {
+				assert.WithinDuration(t, time.Now().Add(tt.wantExpiresIn), got.ExpiresAt, time.Minute)
+			}
+		})
+	}
+}
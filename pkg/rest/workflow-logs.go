@@ -0,0 +1,306 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+)
+
+type (
+	WorkflowLogs struct {
+		WorkflowID string
+		Status     WorkflowStatus
+		Steps      []WorkflowStepLogs
+	}
+
+	WorkflowStepLogs struct {
+		Name     string
+		Title    string
+		Status   string
+		Started  time.Time
+		Finished time.Time
+		// Lines are the log lines of the step, without their line endings
+		Lines []string
+	}
+
+	// WorkflowLogLine is a single line written by a step, as streamed by FollowLogs
+	WorkflowLogLine struct {
+		Step string
+		Text string
+	}
+
+	FollowLogsOptions struct {
+		// Interval is the time between polls of the workflow progress (default: 2s)
+		Interval time.Duration
+	}
+
+	// progressJSON is the document stored at the progress location
+	progressJSON struct {
+		ID     string         `json:"id"`
+		Status string         `json:"status"`
+		Steps  []progressStep `json:"steps"`
+	}
+
+	progressStep struct {
+		Name              string      `json:"name"`
+		Title             string      `json:"title"`
+		Status            string      `json:"status"`
+		CreationTimeStamp int64       `json:"creationTimeStamp"`
+		FinishTimeStamp   int64       `json:"finishTimeStamp"`
+		Logs              progressLog `json:"logs"`
+	}
+
+	// progressLog is the list of log chunks of a step. Each chunk may hold several lines, or part of a line
+	progressLog []string
+)
+
+const defaultFollowLogsInterval = 2 * time.Second
+
+func (w *workflow) Logs(ctx context.Context, id string) (*WorkflowLogs, error) {
+	wf, err := w.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := w.progress(ctx, wf)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := &WorkflowLogs{
+		WorkflowID: wf.ID,
+		Status:     wf.StatusValue(),
+		Steps:      make([]WorkflowStepLogs, 0, len(p.Steps)),
+	}
+	for _, step := range p.Steps {
+		logs.Steps = append(logs.Steps, step.toStepLogs())
+	}
+
+	return logs, nil
+}
+
+func (w *workflow) FollowLogs(ctx context.Context, id string, opt *FollowLogsOptions) (<-chan WorkflowLogLine, <-chan error) {
+	if opt == nil {
+		opt = &FollowLogsOptions{}
+	}
+
+	interval := opt.Interval
+	if interval <= 0 {
+		interval = defaultFollowLogsInterval
+	}
+
+	lines := make(chan WorkflowLogLine)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		err := w.followLogs(ctx, id, interval, lines)
+		close(lines)
+		errc <- err
+	}()
+
+	return lines, errc
+}
+
+func (w *workflow) FollowLogsReader(ctx context.Context, id string, opt *FollowLogsOptions) io.ReadCloser {
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	lines, errc := w.FollowLogs(ctx, id, opt)
+	go func() {
+		defer cancel()
+		var err error
+		for line := range lines {
+			if err != nil {
+				continue
+			}
+
+			if _, err = io.WriteString(pw, line.Text+"\n"); err != nil {
+				// the reader was closed, stop following
+				cancel()
+			}
+		}
+
+		if followErr := <-errc; err == nil {
+			err = followErr
+		}
+
+		pw.CloseWithError(err)
+	}()
+
+	return &cancelReadCloser{ReadCloser: pr, cancel: cancel}
+}
+
+// followLogs polls the progress of the workflow, and sends the lines that were not sent yet, until the workflow is done
+func (w *workflow) followLogs(ctx context.Context, id string, interval time.Duration, lines chan<- WorkflowLogLine) error {
+	// sent holds the number of chunks that were sent for each step, pending holds the partial last line of each step.
+	// Steps are keyed by their index, as step names are not unique (e.g. a step that runs more than once)
+	sent := map[int]int{}
+	pending := map[int]string{}
+	for {
+		wf, err := w.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		p, err := w.progress(ctx, wf)
+		if err != nil && !errors.Is(err, client.ErrNotFound) {
+			return err
+		}
+
+		done := wf.StatusValue().IsFinal()
+		if p != nil {
+			for i, step := range p.Steps {
+				chunks := step.Logs[min(sent[i], len(step.Logs)):]
+				sent[i] += len(chunks)
+				text := pending[i] + strings.Join(chunks, "")
+				complete, partial := splitLogLines(text)
+				pending[i] = partial
+				if done && partial != "" {
+					complete = append(complete, partial)
+				}
+
+				for _, line := range complete {
+					select {
+					case lines <- WorkflowLogLine{Step: step.Name, Text: line}:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+			}
+		}
+
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// progress downloads the progress document of the workflow from its location, without the auth token.
+// It fails with client.ErrNotFound when the workflow has no progress yet (e.g. a pending workflow)
+func (w *workflow) progress(ctx context.Context, wf *Workflow) (*progressJSON, error) {
+	if wf.Progress == "" {
+		return nil, fmt.Errorf("failed getting workflow logs: workflow %q has no progress: %w", wf.ID, client.ErrNotFound)
+	}
+
+	pr, err := (&progress{client: w.codefresh}).Get(ctx, wf.Progress)
+	if err != nil {
+		return nil, err
+	}
+
+	if pr.Location.URL == "" {
+		return nil, fmt.Errorf("failed getting workflow logs: progress %q has no location: %w", pr.ID, client.ErrNotFound)
+	}
+
+	body, err := w.codefresh.Download(ctx, pr.Location.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed downloading workflow logs: %w", err)
+	}
+
+	defer body.Close()
+	result := &progressJSON{}
+	err = json.NewDecoder(body).Decode(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling workflow logs: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s progressStep) toStepLogs() WorkflowStepLogs {
+	lines, partial := splitLogLines(strings.Join(s.Logs, ""))
+	if partial != "" {
+		lines = append(lines, partial)
+	}
+
+	return WorkflowStepLogs{
+		Name:     s.Name,
+		Title:    s.Title,
+		Status:   s.Status,
+		Started:  unixTime(s.CreationTimeStamp),
+		Finished: unixTime(s.FinishTimeStamp),
+		Lines:    lines,
+	}
+}
+
+// UnmarshalJSON accepts the chunks as an array, or as an object keyed by their index
+func (l *progressLog) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, (*[]string)(l))
+	}
+
+	byIndex := map[string]string{}
+	err := json.Unmarshal(data, &byIndex)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(byIndex))
+	for k := range byIndex {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		if errA != nil || errB != nil {
+			return keys[i] < keys[j]
+		}
+
+		return a < b
+	})
+	logs := make(progressLog, 0, len(keys))
+	for _, k := range keys {
+		logs = append(logs, byIndex[k])
+	}
+
+	*l = logs
+	return nil
+}
+
+// splitLogLines returns the complete lines of text, and the partial line after the last line ending
+func splitLogLines(text string) (lines []string, partial string) {
+	parts := strings.Split(text, "\n")
+	lines = make([]string, 0, len(parts)-1)
+	for _, line := range parts[:len(parts)-1] {
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+	}
+
+	return lines, parts[len(parts)-1]
+}
+
+// unixTime converts a timestamp in seconds or milliseconds, returns the zero time for 0
+func unixTime(ts int64) time.Time {
+	switch {
+	case ts == 0:
+		return time.Time{}
+	case ts > 1e11:
+		return time.UnixMilli(ts)
+	default:
+		return time.Unix(ts, 0)
+	}
+}
+
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelReadCloser) Close() error {
+	r.cancel()
+	return r.ReadCloser.Close()
+}
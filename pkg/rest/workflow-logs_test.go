@@ -0,0 +1,204 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codefresh-io/go-sdk/pkg/mocks"
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockWorkflowProgress serves a workflow, its progress and the progress document, the next poll serves the next item
+func mockWorkflowProgress(t *testing.T, rt *mocks.MockRoundTripper, statuses []string, documents []string) {
+	var poll atomic.Int32
+	rt.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		i := min(int(poll.Load()), len(statuses)-1)
+		body := ""
+		switch req.URL.Host + req.URL.Path {
+		case "some.host/api/builds/some-id":
+			body = `{"id": "some-id", "status": "` + statuses[i] + `", "progress": "some-progress"}`
+		case "some.host/api/progress/some-progress":
+			body = `{"id": "some-progress", "location": {"type": "s3", "url": "https://storage.host/progress.json?sig=abc"}}`
+		case "storage.host/progress.json":
+			assert.Empty(t, req.Header.Get("Authorization"))
+			body = documents[i]
+			poll.Add(1)
+		default:
+			t.Errorf("unexpected request %s", req.URL)
+		}
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	})
+}
+
+func Test_workflow_Logs(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockWorkflowProgress(t, mockRT, []string{"success"}, []string{`{
+		"id": "some-progress",
+		"status": "success",
+		"steps": [
+			{"name": "Initializing Process", "status": "success", "creationTimeStamp": 1700000000, "finishTimeStamp": 1700000005, "logs": ["start\r\n", "pulling ", "image\r\n"]},
+			{"name": "build", "title": "Building", "status": "success", "logs": {"1": "second\n", "0": "first\n", "10": "last"}}
+		]
+	}`})
+
+	w := &workflow{
+		codefresh: cfClient,
+	}
+	got, err := w.Logs(context.Background(), "some-id")
+	assert.NoError(t, err)
+	assert.Equal(t, &WorkflowLogs{
+		WorkflowID: "some-id",
+		Status:     "success",
+		Steps: []WorkflowStepLogs{
+			{
+				Name:     "Initializing Process",
+				Status:   "success",
+				Started:  time.Unix(1700000000, 0),
+				Finished: time.Unix(1700000005, 0),
+				Lines:    []string{"start", "pulling image"},
+			},
+			{
+				Name:   "build",
+				Title:  "Building",
+				Status: "success",
+				Lines:  []string{"first", "second", "last"},
+			},
+		},
+	}, got)
+}
+
+func Test_workflow_FollowLogs(t *testing.T) {
+	documents := []string{
+		`{"steps": [{"name": "clone", "logs": ["cloning\n", "done"]}]}`,
+		`{"steps": [{"name": "clone", "logs": ["cloning\n", "done", " cloning\n"]}, {"name": "build", "logs": ["building\n"]}]}`,
+		`{"steps": [{"name": "clone", "logs": ["cloning\n", "done", " cloning\n"]}, {"name": "build", "logs": ["building\n", "built"]}]}`,
+	}
+	statuses := []string{"running", "running", "success"}
+	t.Run("should stream the new lines until the workflow is done", func(t *testing.T) {
+		cfClient, mockRT := utils.NewMockClient(t)
+		mockWorkflowProgress(t, mockRT, statuses, documents)
+		w := &workflow{
+			codefresh: cfClient,
+		}
+		lines, errc := w.FollowLogs(context.Background(), "some-id", &FollowLogsOptions{Interval: time.Millisecond})
+		got := []WorkflowLogLine{}
+		for line := range lines {
+			got = append(got, line)
+		}
+
+		assert.NoError(t, <-errc)
+		assert.Equal(t, []WorkflowLogLine{
+			{Step: "clone", Text: "cloning"},
+			{Step: "clone", Text: "done cloning"},
+			{Step: "build", Text: "building"},
+			{Step: "build", Text: "built"},
+		}, got)
+	})
+	t.Run("should stream the lines as a reader", func(t *testing.T) {
+		cfClient, mockRT := utils.NewMockClient(t)
+		mockWorkflowProgress(t, mockRT, statuses, documents)
+		w := &workflow{
+			codefresh: cfClient,
+		}
+		r := w.FollowLogsReader(context.Background(), "some-id", &FollowLogsOptions{Interval: time.Millisecond})
+		defer r.Close()
+		got, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, "cloning\ndone cloning\nbuilding\nbuilt\n", string(got))
+	})
+	t.Run("should follow steps with the same name separately", func(t *testing.T) {
+		cfClient, mockRT := utils.NewMockClient(t)
+		mockWorkflowProgress(t, mockRT, []string{"running", "success"}, []string{
+			`{"steps": [{"name": "build", "logs": ["first\n", "second\n"]}]}`,
+			`{"steps": [{"name": "build", "logs": ["first\n", "second\n"]}, {"name": "build", "logs": ["retry\n"]}]}`,
+		})
+		w := &workflow{
+			codefresh: cfClient,
+		}
+		lines, errc := w.FollowLogs(context.Background(), "some-id", &FollowLogsOptions{Interval: time.Millisecond})
+		got := []WorkflowLogLine{}
+		for line := range lines {
+			got = append(got, line)
+		}
+
+		assert.NoError(t, <-errc)
+		assert.Equal(t, []WorkflowLogLine{
+			{Step: "build", Text: "first"},
+			{Step: "build", Text: "second"},
+			{Step: "build", Text: "retry"},
+		}, got)
+	})
+	t.Run("should wait for the progress of a pending workflow", func(t *testing.T) {
+		cfClient, mockRT := utils.NewMockClient(t)
+		var polls atomic.Int32
+		mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+			body := ""
+			switch req.URL.Host + req.URL.Path {
+			case "some.host/api/builds/some-id":
+				switch polls.Add(1) {
+				case 1:
+					body = `{"id": "some-id", "status": "pending"}`
+				case 2:
+					body = `{"id": "some-id", "status": "running", "progress": "some-progress"}`
+				default:
+					body = `{"id": "some-id", "status": "success", "progress": "some-progress"}`
+				}
+			case "some.host/api/progress/some-progress":
+				if polls.Load() == 2 {
+					body = `{"id": "some-progress"}`
+				} else {
+					body = `{"id": "some-progress", "location": {"type": "s3", "url": "https://storage.host/progress.json"}}`
+				}
+			case "storage.host/progress.json":
+				body = `{"steps": [{"name": "build", "logs": ["built\n"]}]}`
+			default:
+				t.Errorf("unexpected request %s", req.URL)
+			}
+
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}, nil
+		})
+		w := &workflow{
+			codefresh: cfClient,
+		}
+		lines, errc := w.FollowLogs(context.Background(), "some-id", &FollowLogsOptions{Interval: time.Millisecond})
+		got := []WorkflowLogLine{}
+		for line := range lines {
+			got = append(got, line)
+		}
+
+		assert.NoError(t, <-errc)
+		assert.Equal(t, []WorkflowLogLine{{Step: "build", Text: "built"}}, got)
+		assert.Equal(t, int32(3), polls.Load())
+	})
+	t.Run("should stop when the context is canceled", func(t *testing.T) {
+		cfClient, mockRT := utils.NewMockClient(t)
+		mockWorkflowProgress(t, mockRT, []string{"running"}, documents[:1])
+		w := &workflow{
+			codefresh: cfClient,
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		lines, errc := w.FollowLogs(ctx, "some-id", &FollowLogsOptions{Interval: time.Millisecond})
+		got := []WorkflowLogLine{}
+		for line := range lines {
+			got = append(got, line)
+		}
+
+		assert.ErrorIs(t, <-errc, context.DeadlineExceeded)
+		assert.Equal(t, []WorkflowLogLine{{Step: "clone", Text: "cloning"}}, got)
+	})
+}
@@ -5,36 +5,193 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/codefresh-io/go-sdk/pkg/client"
 )
 
 type (
+	// Deprecated: use WorkflowV2API instead
 	WorkflowAPI interface {
 		Get(string) (*Workflow, error)
 		WaitForStatus(string, string, time.Duration, time.Duration) error
 	}
 
+	WorkflowV2API interface {
+		// Approve approves the pending-approval step of the workflow
+		Approve(ctx context.Context, id string) (*Workflow, error)
+		// Deny denies the pending-approval step of the workflow
+		Deny(ctx context.Context, id string) (*Workflow, error)
+		// FollowLogs sends the log lines of the workflow as they are written, until it is done.
+		// The error channel receives a single value (nil on success) after the lines channel is closed
+		FollowLogs(ctx context.Context, id string, opt *FollowLogsOptions) (<-chan WorkflowLogLine, <-chan error)
+		// FollowLogsReader is FollowLogs as a reader of the lines, closing it stops following
+		FollowLogsReader(ctx context.Context, id string, opt *FollowLogsOptions) io.ReadCloser
+		Get(ctx context.Context, id string) (*Workflow, error)
+		// List returns a single page of workflows, filtered by the options. Use ListPages to get all of them
+		List(ctx context.Context, opt *WorkflowListOptions) ([]Workflow, error)
+		// ListPages calls fn with each page of workflows, until there are no more pages or fn returns an error
+		ListPages(ctx context.Context, opt *WorkflowListOptions, fn func(page []Workflow) error) error
+		// Logs returns the logs of all steps of the workflow, as they are when called
+		Logs(ctx context.Context, id string) (*WorkflowLogs, error)
+		// Restart starts a new workflow with the same options, and returns it
+		Restart(ctx context.Context, id string, opt *RestartOptions) (*Workflow, error)
+		Terminate(ctx context.Context, id string) (*Workflow, error)
+		// WaitForCompletion polls the workflow until it reaches a final status, and returns it
+		WaitForCompletion(ctx context.Context, id string, opt *WaitOptions) (*Workflow, error)
+		// WaitForStatus fails early when the workflow reaches a different final status,
+		// and with a *WaitTimeoutError when the timeout passes first
+		WaitForStatus(ctx context.Context, id string, status string, interval time.Duration, timeout time.Duration) error
+	}
+
 	workflow struct {
 		codefresh *client.CfClient
 	}
 
+	// workflowShim implements the deprecated WorkflowAPI
+	workflowShim struct {
+		v2 *workflow
+	}
+
 	Workflow struct {
 		ID                 string    `json:"id"`
 		Status             string    `json:"status"`
 		UserYamlDescriptor string    `json:"userYamlDescriptor"`
 		Progress           string    `json:"progress"`
+		PipelineID         string    `json:"pipeline,omitempty"`
+		PipelineName       string    `json:"pipelineName,omitempty"`
+		Branch             string    `json:"branchName,omitempty"`
+		Revision           string    `json:"revision,omitempty"`
+		Trigger            string    `json:"trigger,omitempty"`
 		Created            time.Time `json:"created"`
 		Updated            time.Time `json:"updated"`
 		Finished           time.Time `json:"finished"`
 	}
+
+	WorkflowListOptions struct {
+		ListOptions
+		// PipelineIDs filters the workflows of these pipelines
+		PipelineIDs []string
+		// Statuses filters the workflows with one of these statuses
+		Statuses []WorkflowStatus
+		Branch   string
+		// Trigger filters by what started the workflow (e.g. "build", "webhook", "cron")
+		Trigger string
+		// From and To filter the workflows that were created in the date range
+		From time.Time
+		To   time.Time
+	}
+
+	RestartOptions struct {
+		// FromFailedStep restarts the workflow from the step that failed, instead of from the beginning
+		FromFailedStep bool
+	}
+
+	WorkflowStatus string
+
+	WaitOptions struct {
+		// Interval is the time before the second poll (default: 2s)
+		Interval time.Duration
+		// MaxInterval caps the interval as it grows (default: 30s)
+		MaxInterval time.Duration
+		// Multiplier grows the interval after each poll (default: 1.5, use 1 for a constant interval)
+		Multiplier float64
+		// Timeout stops waiting with a *WaitTimeoutError (default: only the context deadline)
+		Timeout time.Duration
+		// OnProgress is called with the workflow after each poll
+		OnProgress func(wf *Workflow)
+	}
+
+	// WaitTimeoutError is returned when the workflow did not complete in time
+	WaitTimeoutError struct {
+		WorkflowID string
+		// LastStatus is the status of the last poll, empty if there was none
+		LastStatus WorkflowStatus
+		err        error
+	}
+
+	getWorkflowsResponse struct {
+		Workflows struct {
+			Docs []Workflow `json:"docs"`
+		} `json:"workflows"`
+	}
 )
 
-func (w *workflow) Get(id string) (*Workflow, error) {
-	res, err := w.codefresh.RestAPI(context.TODO(), &client.RequestOptions{
+func (w *workflow) Approve(ctx context.Context, id string) (*Workflow, error) {
+	_, err := w.codefresh.RestAPI(ctx, &client.RequestOptions{
+		Method: "POST",
+		Path:   fmt.Sprintf("/api/builds/%s/approve", url.PathEscape(id)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed approving workflow: %w", err)
+	}
+
+	return w.Get(ctx, id)
+}
+
+func (w *workflow) Deny(ctx context.Context, id string) (*Workflow, error) {
+	_, err := w.codefresh.RestAPI(ctx, &client.RequestOptions{
+		Method: "POST",
+		Path:   fmt.Sprintf("/api/builds/%s/deny", url.PathEscape(id)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed denying workflow: %w", err)
+	}
+
+	return w.Get(ctx, id)
+}
+
+const (
+	WorkflowStatusPending         WorkflowStatus = "pending"
+	WorkflowStatusElected         WorkflowStatus = "elected"
+	WorkflowStatusRunning         WorkflowStatus = "running"
+	WorkflowStatusPendingApproval WorkflowStatus = "pending-approval"
+	WorkflowStatusTerminating     WorkflowStatus = "terminating"
+	WorkflowStatusSuccess         WorkflowStatus = "success"
+	WorkflowStatusError           WorkflowStatus = "error"
+	WorkflowStatusTerminated      WorkflowStatus = "terminated"
+	WorkflowStatusDenied          WorkflowStatus = "denied"
+)
+
+// errWaitTimeout is returned by waitFor when the timeout passed before the condition was met
+var errWaitTimeout = errors.New("timed out")
+
+const (
+	defaultWaitInterval    = 2 * time.Second
+	defaultWaitMaxInterval = 30 * time.Second
+	defaultWaitMultiplier  = 1.5
+)
+
+// IsFinal returns true for the statuses that will not change anymore
+func (s WorkflowStatus) IsFinal() bool {
+	switch s {
+	case WorkflowStatusSuccess, WorkflowStatusError, WorkflowStatusTerminated, WorkflowStatusDenied:
+		return true
+	}
+
+	return false
+}
+
+// StatusValue returns the status of the workflow as a WorkflowStatus
+func (w Workflow) StatusValue() WorkflowStatus {
+	return WorkflowStatus(w.Status)
+}
+
+func (e *WaitTimeoutError) Error() string {
+	return fmt.Sprintf("timed out waiting for workflow %q, last status: %q", e.WorkflowID, e.LastStatus)
+}
+
+func (e *WaitTimeoutError) Unwrap() error {
+	return e.err
+}
+
+func (w *workflow) Get(ctx context.Context, id string) (*Workflow, error) {
+	res, err := w.codefresh.RestAPI(ctx, &client.RequestOptions{
 		Method: "GET",
-		Path:   fmt.Sprintf("/api/builds/%s", id),
+		Path:   fmt.Sprintf("/api/builds/%s", url.PathEscape(id)),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed getting a workflow: %w", err)
@@ -44,32 +201,256 @@ func (w *workflow) Get(id string) (*Workflow, error) {
 	return result, json.Unmarshal(res, result)
 }
 
-func (w *workflow) WaitForStatus(id string, status string, interval time.Duration, timeout time.Duration) error {
-	return waitFor(interval, timeout, func() (bool, error) {
-		res, err := w.Get(id)
+func (w *workflow) List(ctx context.Context, opt *WorkflowListOptions) ([]Workflow, error) {
+	if opt == nil {
+		opt = &WorkflowListOptions{}
+	}
+
+	query := opt.query()
+	if opt.Limit > 0 {
+		query["limit"] = strconv.Itoa(opt.Limit)
+	}
+
+	if opt.Offset > 0 {
+		query["offset"] = strconv.Itoa(opt.Offset)
+	}
+
+	return w.list(ctx, query)
+}
+
+func (w *workflow) ListPages(ctx context.Context, opt *WorkflowListOptions, fn func(page []Workflow) error) error {
+	if opt == nil {
+		opt = &WorkflowListOptions{}
+	}
+
+	return forEachOffsetPage(ctx, &opt.ListOptions, func(ctx context.Context, limit, offset int) ([]Workflow, int, error) {
+		query := opt.query()
+		for k, v := range pageQuery(limit, offset) {
+			query[k] = v
+		}
+
+		page, err := w.list(ctx, query)
+		return page, -1, err
+	}, func(w Workflow) string { return w.ID }, fn)
+}
+
+func (w *workflow) list(ctx context.Context, query map[string]any) ([]Workflow, error) {
+	res, err := w.codefresh.RestAPI(ctx, &client.RequestOptions{
+		Method: "GET",
+		Path:   "/api/workflow",
+		Query:  query,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing workflows: %w", err)
+	}
+
+	result := &getWorkflowsResponse{}
+	err = json.Unmarshal(res, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling workflows: %w", err)
+	}
+
+	return result.Workflows.Docs, nil
+}
+
+func (w *workflow) Restart(ctx context.Context, id string, opt *RestartOptions) (*Workflow, error) {
+	if opt == nil {
+		opt = &RestartOptions{}
+	}
+
+	query := map[string]any{}
+	if opt.FromFailedStep {
+		query["restartFromFailedStep"] = "true"
+	}
+
+	// every call starts a new workflow, so it must not be retried
+	res, err := w.codefresh.RestAPI(ctx, &client.RequestOptions{
+		Method:        "GET",
+		Path:          fmt.Sprintf("/api/builds/rebuild/%s", url.PathEscape(id)),
+		Query:         query,
+		NotIdempotent: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed restarting workflow: %w", err)
+	}
+
+	result, err := parseRunResult(res)
+	if err != nil {
+		return nil, fmt.Errorf("failed restarting workflow: %w", err)
+	}
+
+	return w.Get(ctx, result.WorkflowID)
+}
+
+func (w *workflow) Terminate(ctx context.Context, id string) (*Workflow, error) {
+	_, err := w.codefresh.RestAPI(ctx, &client.RequestOptions{
+		Method: "POST",
+		Path:   fmt.Sprintf("/api/builds/%s/terminate", url.PathEscape(id)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed terminating workflow: %w", err)
+	}
+
+	return w.Get(ctx, id)
+}
+
+// query returns the filters of the options as api query params, without limit and offset
+func (o *WorkflowListOptions) query() map[string]any {
+	query := map[string]any{}
+	if len(o.PipelineIDs) > 0 {
+		query["pipeline"] = o.PipelineIDs
+	}
+
+	if len(o.Statuses) > 0 {
+		statuses := make([]string, len(o.Statuses))
+		for i, status := range o.Statuses {
+			statuses[i] = string(status)
+		}
+
+		query["status"] = statuses
+	}
+
+	if o.Branch != "" {
+		query["branchName"] = o.Branch
+	}
+
+	if o.Trigger != "" {
+		query["trigger"] = o.Trigger
+	}
+
+	if !o.From.IsZero() {
+		query["startDate"] = o.From.UTC().Format(time.RFC3339)
+	}
+
+	if !o.To.IsZero() {
+		query["endDate"] = o.To.UTC().Format(time.RFC3339)
+	}
+
+	return query
+}
+
+func (w *workflow) WaitForStatus(ctx context.Context, id string, status string, interval time.Duration, timeout time.Duration) error {
+	var lastStatus WorkflowStatus
+	err := waitFor(ctx, interval, timeout, func(ctx context.Context) (bool, error) {
+		res, err := w.Get(ctx, id)
 		if err != nil {
 			return false, err
 		}
 
+		lastStatus = res.StatusValue()
 		if res.Status == status {
 			return true, nil
 		}
 
+		if res.StatusValue().IsFinal() {
+			return false, fmt.Errorf("workflow %q ended with status %q, instead of %q", id, res.Status, status)
+		}
+
 		return false, nil
 	})
+	if errors.Is(err, errWaitTimeout) {
+		return &WaitTimeoutError{WorkflowID: id, LastStatus: lastStatus, err: context.DeadlineExceeded}
+	}
+
+	return err
+}
+
+func (w *workflow) WaitForCompletion(ctx context.Context, id string, opt *WaitOptions) (*Workflow, error) {
+	if opt == nil {
+		opt = &WaitOptions{}
+	}
+
+	interval := opt.Interval
+	if interval <= 0 {
+		interval = defaultWaitInterval
+	}
+
+	maxInterval := opt.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultWaitMaxInterval
+	}
+
+	multiplier := opt.Multiplier
+	if multiplier < 1 {
+		multiplier = defaultWaitMultiplier
+	}
+
+	if opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.Timeout)
+		defer cancel()
+	}
+
+	var lastStatus WorkflowStatus
+	for {
+		wf, err := w.Get(ctx, id)
+		if err != nil && ctx.Err() == nil {
+			return nil, err
+		}
+
+		if err == nil {
+			lastStatus = wf.StatusValue()
+			if opt.OnProgress != nil {
+				opt.OnProgress(wf)
+			}
+
+			if wf.StatusValue().IsFinal() {
+				return wf, nil
+			}
+		}
+
+		err = sleepContext(ctx, interval)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, &WaitTimeoutError{WorkflowID: id, LastStatus: lastStatus, err: err}
+			}
+
+			return nil, err
+		}
+
+		interval = min(time.Duration(float64(interval)*multiplier), maxInterval)
+	}
+}
+
+// sleepContext waits for d, or returns the context error when it is done first
+func (w *workflowShim) Get(id string) (*Workflow, error) {
+	return w.v2.Get(context.Background(), id)
+}
+
+func (w *workflowShim) WaitForStatus(id string, status string, interval time.Duration, timeout time.Duration) error {
+	return w.v2.WaitForStatus(context.Background(), id, status, interval, timeout)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
 }
 
-func waitFor(interval time.Duration, timeout time.Duration, execution func() (bool, error)) error {
-	t := time.After(timeout)
+func waitFor(ctx context.Context, interval time.Duration, timeout time.Duration, execution func(ctx context.Context) (bool, error)) error {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
 	ticker := time.NewTicker(interval)
-	// Keep trying until we're timed out or got a result or got an error
+	defer ticker.Stop()
+	// Keep trying until we're timed out or canceled or got a result or got an error
 	for {
 		select {
+		case <-ctx.Done():
+			return ctx.Err()
 		// Got a timeout! fail with a timeout error
-		case <-t:
-			return errors.New("timed out")
+		case <-t.C:
+			return errWaitTimeout
 		case <-ticker.C:
-			ok, err := execution()
+			ok, err := execution(ctx)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,418 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+	"github.com/codefresh-io/go-sdk/pkg/mocks"
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_workflow_WaitForStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		timeout  time.Duration
+		ctx      func() (context.Context, context.CancelFunc)
+		wantErr  string
+		beforeFn func(rt *mocks.MockRoundTripper)
+	}{
+		{
+			name:    "should return when status is reached",
+			status:  "success",
+			timeout: time.Second,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			beforeFn: func(rt *mocks.MockRoundTripper) {
+				rt.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, "/api/builds/some-id", req.URL.Path)
+					bodyReader := io.NopCloser(strings.NewReader(`{"id": "some-id", "status": "success"}`))
+					return &http.Response{
+						StatusCode: 200,
+						Body:       bodyReader,
+					}, nil
+				})
+			},
+		},
+		{
+			name:    "should stop waiting when context is canceled",
+			status:  "success",
+			timeout: time.Minute,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 20*time.Millisecond)
+			},
+			wantErr: "context deadline exceeded",
+			beforeFn: func(rt *mocks.MockRoundTripper) {
+				rt.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					bodyReader := io.NopCloser(strings.NewReader(`{"id": "some-id", "status": "running"}`))
+					return &http.Response{
+						StatusCode: 200,
+						Body:       bodyReader,
+					}, nil
+				}).Maybe()
+			},
+		},
+		{
+			name:    "should fail when the workflow ends with another status",
+			status:  "success",
+			timeout: time.Minute,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			wantErr: `workflow "some-id" ended with status "error", instead of "success"`,
+			beforeFn: func(rt *mocks.MockRoundTripper) {
+				rt.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					bodyReader := io.NopCloser(strings.NewReader(`{"id": "some-id", "status": "error"}`))
+					return &http.Response{
+						StatusCode: 200,
+						Body:       bodyReader,
+					}, nil
+				})
+			},
+		},
+		{
+			name:    "should fail on timeout",
+			status:  "success",
+			timeout: 20 * time.Millisecond,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			wantErr: `timed out waiting for workflow "some-id", last status: "running"`,
+			beforeFn: func(rt *mocks.MockRoundTripper) {
+				rt.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+					bodyReader := io.NopCloser(strings.NewReader(`{"id": "some-id", "status": "running"}`))
+					return &http.Response{
+						StatusCode: 200,
+						Body:       bodyReader,
+					}, nil
+				}).Maybe()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			if tt.beforeFn != nil {
+				tt.beforeFn(mockRT)
+			}
+
+			w := &workflow{
+				codefresh: cfClient,
+			}
+			ctx, cancel := tt.ctx()
+			defer cancel()
+			err := w.WaitForStatus(ctx, "some-id", tt.status, 5*time.Millisecond, tt.timeout)
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+
+			if tt.timeout < time.Second && tt.wantErr != "" {
+				var timeoutErr *WaitTimeoutError
+				assert.ErrorAs(t, err, &timeoutErr)
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			}
+		})
+	}
+}
+
+func Test_workflow_actions(t *testing.T) {
+	tests := []struct {
+		name       string
+		action     func(w *workflow) (*Workflow, error)
+		wantMethod string
+		wantPath   string
+		wantQuery  string
+		response   string
+		wantID     string
+		wantErr    string
+	}{
+		{
+			name: "should approve",
+			action: func(w *workflow) (*Workflow, error) {
+				return w.Approve(context.Background(), "some-id")
+			},
+			wantMethod: "POST",
+			wantPath:   "/api/builds/some-id/approve",
+			wantID:     "some-id",
+		},
+		{
+			name: "should deny",
+			action: func(w *workflow) (*Workflow, error) {
+				return w.Deny(context.Background(), "some-id")
+			},
+			wantMethod: "POST",
+			wantPath:   "/api/builds/some-id/deny",
+			wantID:     "some-id",
+		},
+		{
+			name: "should terminate",
+			action: func(w *workflow) (*Workflow, error) {
+				return w.Terminate(context.Background(), "some-id")
+			},
+			wantMethod: "POST",
+			wantPath:   "/api/builds/some-id/terminate",
+			wantID:     "some-id",
+		},
+		{
+			name: "should restart and return the new workflow",
+			action: func(w *workflow) (*Workflow, error) {
+				return w.Restart(context.Background(), "some-id", nil)
+			},
+			wantMethod: "GET",
+			wantPath:   "/api/builds/rebuild/some-id",
+			response:   `"new-id"`,
+			wantID:     "new-id",
+		},
+		{
+			name: "should restart from the failed step",
+			action: func(w *workflow) (*Workflow, error) {
+				return w.Restart(context.Background(), "some-id", &RestartOptions{FromFailedStep: true})
+			},
+			wantMethod: "GET",
+			wantPath:   "/api/builds/rebuild/some-id",
+			wantQuery:  "restartFromFailedStep=true",
+			response:   `"new-id"`,
+			wantID:     "new-id",
+		},
+		{
+			name: "should fail restarting when the response has no workflow id",
+			action: func(w *workflow) (*Workflow, error) {
+				return w.Restart(context.Background(), "some-id", nil)
+			},
+			wantMethod: "GET",
+			wantPath:   "/api/builds/rebuild/some-id",
+			response:   `""`,
+			wantErr:    "failed restarting workflow: empty build id in response",
+		},
+		{
+			name: "should escape the workflow id",
+			action: func(w *workflow) (*Workflow, error) {
+				return w.Get(context.Background(), "some/id")
+			},
+			wantMethod: "GET",
+			wantPath:   "/api/builds/some%2Fid",
+			wantID:     "some/id",
+		},
+		{
+			name: "should fail when the action fails",
+			action: func(w *workflow) (*Workflow, error) {
+				return w.Approve(context.Background(), "some-id")
+			},
+			wantMethod: "POST",
+			wantPath:   "/api/builds/some-id/approve",
+			wantErr:    "failed approving workflow: API error: : some error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				if req.URL.EscapedPath() == "/api/builds/"+url.PathEscape(tt.wantID) {
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(strings.NewReader(`{"id": "` + tt.wantID + `", "status": "running"}`)),
+					}, nil
+				}
+
+				assert.Equal(t, tt.wantMethod, req.Method)
+				assert.Equal(t, tt.wantPath, req.URL.EscapedPath())
+				assert.Equal(t, tt.wantQuery, req.URL.RawQuery)
+				if tt.wantErr != "" && tt.response == "" {
+					return &http.Response{
+						StatusCode: 500,
+						Body:       io.NopCloser(strings.NewReader("some error")),
+					}, nil
+				}
+
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(tt.response)),
+				}, nil
+			})
+
+			w := &workflow{
+				codefresh: cfClient,
+			}
+			got, err := tt.action(w)
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.wantID, got.ID)
+		})
+	}
+}
+
+func Test_workflow_List(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/api/workflow", req.URL.Path)
+		assert.Equal(t, "branchName=main&endDate=2024-01-02T00%3A00%3A00Z&limit=10&offset=20&pipeline=p1&pipeline=p2&startDate=2024-01-01T00%3A00%3A00Z&status=error&status=terminated&trigger=webhook", req.URL.RawQuery)
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"workflows": {"docs": [{"id": "w1", "status": "error", "pipeline": "p1", "branchName": "main"}]}}`)),
+		}, nil
+	})
+
+	w := &workflow{
+		codefresh: cfClient,
+	}
+	got, err := w.List(context.Background(), &WorkflowListOptions{
+		ListOptions: ListOptions{Limit: 10, Offset: 20},
+		PipelineIDs: []string{"p1", "p2"},
+		Statuses:    []WorkflowStatus{WorkflowStatusError, WorkflowStatusTerminated},
+		Branch:      "main",
+		Trigger:     "webhook",
+		From:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:          time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Workflow{{ID: "w1", Status: "error", PipelineID: "p1", Branch: "main"}}, got)
+}
+
+func Test_workflow_ListPages(t *testing.T) {
+	cfClient, mockRT := utils.NewMockClient(t)
+	var offsets []string
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/api/workflow", req.URL.Path)
+		assert.Equal(t, "main", req.URL.Query().Get("branchName"))
+		assert.Equal(t, "2", req.URL.Query().Get("limit"))
+		offset := req.URL.Query().Get("offset")
+		offsets = append(offsets, offset)
+		body := `{"workflows": {"docs": [{"id": "w1"}, {"id": "w2"}]}}`
+		if offset == "2" {
+			body = `{"workflows": {"docs": [{"id": "w3"}]}}`
+		}
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	})
+
+	w := &workflow{
+		codefresh: cfClient,
+	}
+	var ids []string
+	err := w.ListPages(context.Background(), &WorkflowListOptions{
+		ListOptions: ListOptions{Limit: 2},
+		Branch:      "main",
+	}, func(page []Workflow) error {
+		for _, wf := range page {
+			ids = append(ids, wf.ID)
+		}
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0", "2"}, offsets)
+	assert.Equal(t, []string{"w1", "w2", "w3"}, ids)
+}
+
+func Test_workflow_Restart_NoRetry(t *testing.T) {
+	mockRT := mocks.NewMockRoundTripper(t)
+	cfClient := client.NewCfClientWithOptions(&client.ClientOptions{
+		Host:   "https://some.host",
+		Token:  "some-token",
+		Client: &http.Client{Transport: mockRT},
+		Retry:  &client.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	calls := 0
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+		calls++
+		assert.Equal(t, "/api/builds/rebuild/some%2Fid", req.URL.EscapedPath())
+		return &http.Response{
+			StatusCode: 503,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("unavailable")),
+		}, nil
+	})
+
+	w := &workflow{
+		codefresh: cfClient,
+	}
+	_, err := w.Restart(context.Background(), "some/id", nil)
+	assert.EqualError(t, err, "failed restarting workflow: API error: : unavailable")
+	assert.Equal(t, 1, calls)
+}
+
+func Test_workflow_WaitForCompletion(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []string
+		opt          *WaitOptions
+		wantStatus   WorkflowStatus
+		wantProgress []WorkflowStatus
+		wantErr      string
+		wantTimeout  bool
+	}{
+		{
+			name:         "should return the workflow when it reaches a final status",
+			statuses:     []string{"pending", "running", "error"},
+			wantStatus:   WorkflowStatusError,
+			wantProgress: []WorkflowStatus{WorkflowStatusPending, WorkflowStatusRunning, WorkflowStatusError},
+		},
+		{
+			name:         "should fail with the last status on timeout",
+			statuses:     []string{"pending-approval"},
+			opt:          &WaitOptions{Timeout: 20 * time.Millisecond},
+			wantErr:      `timed out waiting for workflow "some-id", last status: "pending-approval"`,
+			wantTimeout:  true,
+			wantProgress: []WorkflowStatus{WorkflowStatusPendingApproval},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT := utils.NewMockClient(t)
+			poll := 0
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				status := tt.statuses[min(poll, len(tt.statuses)-1)]
+				poll++
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(`{"id": "some-id", "status": "` + status + `"}`)),
+				}, nil
+			})
+
+			opt := tt.opt
+			if opt == nil {
+				opt = &WaitOptions{}
+			}
+
+			opt.Interval = time.Millisecond
+			opt.MaxInterval = 4 * time.Millisecond
+			progress := []WorkflowStatus{}
+			opt.OnProgress = func(wf *Workflow) {
+				if len(progress) == 0 || progress[len(progress)-1] != wf.StatusValue() {
+					progress = append(progress, wf.StatusValue())
+				}
+			}
+
+			w := &workflow{
+				codefresh: cfClient,
+			}
+			got, err := w.WaitForCompletion(context.Background(), "some-id", opt)
+			assert.Equal(t, tt.wantProgress, progress)
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				timeoutErr := &WaitTimeoutError{}
+				assert.Equal(t, tt.wantTimeout, errors.As(err, &timeoutErr))
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+				return
+			}
+
+			assert.Equal(t, tt.wantStatus, got.StatusValue())
+		})
+	}
+}
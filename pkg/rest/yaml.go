@@ -0,0 +1,221 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlToJSON converts yaml to json, keeping the order of mapping keys (sigs.k8s.io/yaml sorts them)
+func yamlToJSON(data []byte) ([]byte, error) {
+	node := &yaml.Node{}
+	err := yaml.Unmarshal(data, node)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	err = writeYamlNode(buf, node)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeYamlNode(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case 0:
+		buf.WriteString("null")
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+
+		return writeYamlNode(buf, node.Content[0])
+	case yaml.AliasNode:
+		return writeYamlNode(buf, node.Alias)
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := writeYamlNode(buf, item); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte(']')
+	case yaml.MappingNode:
+		pairs := yamlMappingPairs(node)
+		buf.WriteByte('{')
+		for i, pair := range pairs {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			key, _ := json.Marshal(pair[0].Value)
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeYamlNode(buf, pair[1]); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte('}')
+	case yaml.ScalarNode:
+		var value any = node.Value
+		// timestamps are kept as written, instead of being reformatted by time.Time
+		if node.ShortTag() != "!!timestamp" {
+			if err := node.Decode(&value); err != nil {
+				return err
+			}
+		}
+
+		b, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed converting %q to json at line %d: %w", node.Value, node.Line, err)
+		}
+
+		buf.Write(b)
+	default:
+		return fmt.Errorf("unsupported yaml node kind %d at line %d", node.Kind, node.Line)
+	}
+
+	return nil
+}
+
+// yamlMappingPairs returns the key/value pairs of a mapping, resolving merge keys ("<<: *anchor")
+func yamlMappingPairs(node *yaml.Node) [][2]*yaml.Node {
+	pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	index := map[string]int{}
+	add := func(key, value *yaml.Node, override bool) {
+		if i, ok := index[key.Value]; ok {
+			if override {
+				pairs[i][1] = value
+			}
+
+			return
+		}
+
+		index[key.Value] = len(pairs)
+		pairs = append(pairs, [2]*yaml.Node{key, value})
+	}
+
+	var merged []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Tag != "!!merge" {
+			add(key, value, true)
+			continue
+		}
+
+		if value.Kind == yaml.AliasNode {
+			value = value.Alias
+		}
+
+		if value.Kind == yaml.SequenceNode {
+			merged = append(merged, value.Content...)
+		} else {
+			merged = append(merged, value)
+		}
+	}
+
+	// explicit keys take precedence over merged ones
+	for _, m := range merged {
+		if m.Kind == yaml.AliasNode {
+			m = m.Alias
+		}
+
+		for _, pair := range yamlMappingPairs(m) {
+			add(pair[0], pair[1], false)
+		}
+	}
+
+	return pairs
+}
+
+// jsonToYAML converts json to yaml, keeping the order of object keys
+func jsonToYAML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := jsonYamlNode(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	err = enc.Encode(node)
+	if err != nil {
+		return nil, err
+	}
+
+	err = enc.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func jsonYamlNode(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if t == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+
+		for dec.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+
+				keyNode := &yaml.Node{}
+				keyNode.SetString(key.(string))
+				node.Content = append(node.Content, keyNode)
+			}
+
+			value, err := jsonYamlNode(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			node.Content = append(node.Content, value)
+		}
+
+		// closing delimiter
+		_, err = dec.Token()
+		return node, err
+	case string:
+		node := &yaml.Node{}
+		node.SetString(t)
+		return node, nil
+	case json.Number:
+		tag := "!!int"
+		if _, err := strconv.ParseInt(t.String(), 10, 64); err != nil {
+			tag = "!!float"
+		}
+
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(t)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
@@ -0,0 +1,52 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_yamlToJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "should keep the order of keys",
+			data: "b: 1\na: [true, null, 1.5]\nc: {z: x, y: '2'}\n",
+			want: `{"b":1,"a":[true,null,1.5],"c":{"z":"x","y":"2"}}`,
+		},
+		{
+			name: "should resolve anchors and merge keys",
+			data: "base: &base\n  image: alpine\n  tag: v1\nstep:\n  <<: *base\n  tag: v2\n",
+			want: `{"base":{"image":"alpine","tag":"v1"},"step":{"tag":"v2","image":"alpine"}}`,
+		},
+		{
+			name: "should keep timestamps as written",
+			data: "date: 2024-01-01\n",
+			want: `{"date":"2024-01-01"}`,
+		},
+		{
+			name: "should return null for an empty document",
+			want: `null`,
+		},
+		{
+			name:    "should fail on invalid yaml",
+			data:    "a: [1",
+			wantErr: "yaml: line 1: did not find expected ',' or ']'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := yamlToJSON([]byte(tt.data))
+			if err != nil || tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
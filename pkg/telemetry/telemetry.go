@@ -0,0 +1,244 @@
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// Option configures the telemetry middleware
+	Option func(cfg *config)
+
+	config struct {
+		tracerProvider trace.TracerProvider
+		meterProvider  metric.MeterProvider
+		propagator     propagation.TextMapPropagator
+	}
+
+	instruments struct {
+		tracer     trace.Tracer
+		propagator propagation.TextMapPropagator
+		duration   metric.Float64Histogram
+		errors     metric.Int64Counter
+	}
+)
+
+const (
+	// ScopeName is the instrumentation scope of the tracer and meter
+	ScopeName = "github.com/codefresh-io/go-sdk/pkg/telemetry"
+
+	DurationMetricName = "codefresh.client.duration"
+	ErrorsMetricName   = "codefresh.client.errors"
+
+	KindKey              = attribute.Key("codefresh.api.kind")
+	RuntimeNameKey       = attribute.Key("codefresh.runtime.name")
+	GraphqlErrorCountKey = attribute.Key("codefresh.graphql.error_count")
+)
+
+// WithTracerProvider sets the tracer provider (default: the global tracer provider)
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(cfg *config) {
+		cfg.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the meter provider (default: the global meter provider)
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(cfg *config) {
+		cfg.meterProvider = mp
+	}
+}
+
+// WithPropagator sets the propagator used to inject the trace context into the request headers (default: the global propagator)
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(cfg *config) {
+		cfg.propagator = p
+	}
+}
+
+// Middleware returns a client middleware that creates a span for every sdk call, propagates the trace context,
+// and records the duration and errors of the calls.
+// GraphQL spans are named after the operation, rest spans after the http method.
+//
+//	mw, err := telemetry.Middleware()
+//	cf, err := codefresh.NewClient(host, client.WithToken(token), client.WithMiddleware(mw))
+func Middleware(opts ...Option) (client.Middleware, error) {
+	cfg := &config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagator:     otel.GetTextMapPropagator(),
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	meter := cfg.meterProvider.Meter(ScopeName)
+	duration, err := meter.Float64Histogram(DurationMetricName,
+		metric.WithDescription("Duration of codefresh api calls"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating duration histogram: %w", err)
+	}
+
+	errors, err := meter.Int64Counter(ErrorsMetricName,
+		metric.WithDescription("Number of failed codefresh api calls"),
+		metric.WithUnit("{call}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating errors counter: %w", err)
+	}
+
+	inst := &instruments{
+		tracer:     cfg.tracerProvider.Tracer(ScopeName),
+		propagator: cfg.propagator,
+		duration:   duration,
+		errors:     errors,
+	}
+	return inst.middleware, nil
+}
+
+func (i *instruments) middleware(next client.Handler) client.Handler {
+	return func(req *http.Request, info client.CallInfo) (*http.Response, error) {
+		attrs := callAttributes(info)
+		ctx, span := i.tracer.Start(req.Context(), spanName(req, info),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...),
+			trace.WithAttributes(spanOnlyAttributes(info)...),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(req.Method),
+				semconv.ServerAddress(req.URL.Hostname()),
+				semconv.URLPath(req.URL.Path),
+			),
+		)
+		defer span.End()
+
+		req = req.WithContext(ctx)
+		i.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+		start := time.Now()
+		res, err := next(req, info)
+		errorType := ""
+		switch {
+		case err != nil:
+			errorType = "transport"
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		case res.StatusCode >= http.StatusBadRequest:
+			errorType = fmt.Sprint(res.StatusCode)
+			span.SetStatus(codes.Error, res.Status)
+		case info.Kind == client.CallKindGraphql:
+			count := graphqlErrorCount(res)
+			span.SetAttributes(GraphqlErrorCountKey.Int(count))
+			if count > 0 {
+				errorType = "graphql"
+				span.SetStatus(codes.Error, fmt.Sprintf("%d graphql errors", count))
+			}
+		}
+
+		if res != nil {
+			span.SetAttributes(semconv.HTTPResponseStatusCode(res.StatusCode))
+			attrs = append(attrs, semconv.HTTPResponseStatusCode(res.StatusCode))
+		}
+
+		if errorType != "" {
+			attrs = append(attrs, semconv.ErrorTypeKey.String(errorType))
+			i.errors.Add(ctx, 1, metric.WithAttributes(attrs...))
+		}
+
+		i.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+		return res, err
+	}
+}
+
+func spanName(req *http.Request, info client.CallInfo) string {
+	if info.Kind == client.CallKindGraphql && info.Operation != "" {
+		return info.Operation
+	}
+
+	return req.Method
+}
+
+// callAttributes are low cardinality attributes, shared by spans and metrics
+func callAttributes(info client.CallInfo) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{KindKey.String(string(info.Kind))}
+	if info.Kind != client.CallKindGraphql {
+		return attrs
+	}
+
+	if info.Operation != "" {
+		attrs = append(attrs, semconv.GraphqlOperationName(info.Operation))
+	}
+
+	if info.OperationType != "" {
+		attrs = append(attrs, semconv.GraphqlOperationTypeKey.String(info.OperationType))
+	}
+
+	return attrs
+}
+
+// spanOnlyAttributes are the attributes that are only set on spans, since their cardinality is too high for metrics
+func spanOnlyAttributes(info client.CallInfo) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{}
+	if runtime := runtimeName(info); runtime != "" {
+		attrs = append(attrs, RuntimeNameKey.String(runtime))
+	}
+
+	return attrs
+}
+
+// runtimeName returns the runtime a graphql call refers to, when it is passed as a variable
+func runtimeName(info client.CallInfo) string {
+	variables, ok := info.Variables.(map[string]any)
+	if !ok {
+		return ""
+	}
+
+	for _, key := range []string{"runtime", "runtimeName"} {
+		if name, ok := variables[key].(string); ok {
+			return name
+		}
+	}
+
+	// runtime operations (GetRuntime, DeleteRuntime, ...) identify the runtime by its name
+	if strings.Contains(info.Operation, "Runtime") {
+		if name, ok := variables["name"].(string); ok {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// graphqlErrorCount reads the number of errors in a graphql response, and restores its body
+func graphqlErrorCount(res *http.Response) int {
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return 0
+	}
+
+	var payload struct {
+		Errors []json.RawMessage `json:"errors"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		return 0
+	}
+
+	return len(payload.Errors)
+}
@@ -0,0 +1,169 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+	"github.com/codefresh-io/go-sdk/pkg/mocks"
+	"github.com/codefresh-io/go-sdk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const getRuntimeQuery = `
+query GetRuntime($name: String!) {
+	runtime(name: $name) {
+		metadata { name }
+	}
+}`
+
+func newTestClient(t *testing.T) (*client.CfClient, *mocks.MockRoundTripper, *tracetest.InMemoryExporter, *sdkmetric.ManualReader) {
+	exporter := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	mw, err := Middleware(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithPropagator(propagation.TraceContext{}),
+	)
+	assert.NoError(t, err)
+
+	cfClient, mockRT := utils.NewMockClient(t)
+	cfClient.Use(mw)
+	return cfClient, mockRT, exporter, reader
+}
+
+func newResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+
+	return attrs
+}
+
+func TestMiddleware_Graphql(t *testing.T) {
+	tests := []struct {
+		name           string
+		response       string
+		wantErrCount   int64
+		wantStatusCode codes.Code
+	}{
+		{
+			name:           "should record a successful graphql call",
+			response:       `{"data": {"runtime": {"metadata": {"name": "some-runtime"}}}}`,
+			wantStatusCode: codes.Unset,
+		},
+		{
+			name:           "should record graphql errors",
+			response:       `{"data": {"runtime": null}, "errors": [{"message": "not found"}, {"message": "other"}]}`,
+			wantErrCount:   2,
+			wantStatusCode: codes.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfClient, mockRT, exporter, reader := newTestClient(t)
+			mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).RunAndReturn(func(req *http.Request) (*http.Response, error) {
+				assert.NotEmpty(t, req.Header.Get("traceparent"))
+				return newResponse(200, tt.response), nil
+			})
+
+			result := map[string]any{}
+			err := cfClient.GraphqlAPI(context.Background(), getRuntimeQuery, map[string]any{"name": "some-runtime"}, &result)
+			assert.NoError(t, err)
+			// the body is still readable after the middleware counted the errors
+			assert.Contains(t, result, "data")
+
+			spans := exporter.GetSpans()
+			assert.Len(t, spans, 1)
+			assert.Equal(t, "GetRuntime", spans[0].Name)
+			assert.Equal(t, tt.wantStatusCode, spans[0].Status.Code)
+			attrs := spanAttributes(spans[0])
+			assert.Equal(t, "graphql", attrs[KindKey].AsString())
+			assert.Equal(t, "query", attrs[semconv.GraphqlOperationTypeKey].AsString())
+			assert.Equal(t, "some-runtime", attrs[RuntimeNameKey].AsString())
+			assert.Equal(t, int64(200), attrs[semconv.HTTPResponseStatusCodeKey].AsInt64())
+			assert.Equal(t, tt.wantErrCount, attrs[GraphqlErrorCountKey].AsInt64())
+
+			metrics := collectMetrics(t, reader)
+			assert.Equal(t, uint64(1), histogramCount(metrics[DurationMetricName]))
+			assert.Equal(t, tt.wantErrCount > 0, metrics[ErrorsMetricName] != nil)
+			for _, dp := range metrics[DurationMetricName].(metricdata.Histogram[float64]).DataPoints {
+				assert.False(t, dp.Attributes.HasValue(RuntimeNameKey), "runtime name must not be a metric attribute")
+			}
+		})
+	}
+}
+
+func TestMiddleware_Rest(t *testing.T) {
+	cfClient, mockRT, exporter, reader := newTestClient(t)
+	mockRT.EXPECT().RoundTrip(mock.AnythingOfType("*http.Request")).Return(newResponse(404, `{"message": "not found"}`), nil)
+
+	_, err := cfClient.RestAPI(context.Background(), &client.RequestOptions{Path: "/api/pipelines/some-id"})
+	assert.ErrorIs(t, err, client.ErrNotFound)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "GET", spans[0].Name)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	attrs := spanAttributes(spans[0])
+	assert.Equal(t, "rest", attrs[KindKey].AsString())
+	assert.Equal(t, "/api/pipelines/some-id", attrs[semconv.URLPathKey].AsString())
+	assert.Equal(t, int64(404), attrs[semconv.HTTPResponseStatusCodeKey].AsInt64())
+
+	metrics := collectMetrics(t, reader)
+	assert.Equal(t, uint64(1), histogramCount(metrics[DurationMetricName]))
+	sum := metrics[ErrorsMetricName].(metricdata.Sum[int64])
+	assert.Len(t, sum.DataPoints, 1)
+	assert.Equal(t, int64(1), sum.DataPoints[0].Value)
+	errorType, _ := sum.DataPoints[0].Attributes.Value(semconv.ErrorTypeKey)
+	assert.Equal(t, "404", errorType.AsString())
+}
+
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	rm := metricdata.ResourceMetrics{}
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+	metrics := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	return metrics
+}
+
+func histogramCount(data metricdata.Aggregation) uint64 {
+	histogram, ok := data.(metricdata.Histogram[float64])
+	if !ok {
+		return 0
+	}
+
+	var count uint64
+	for _, dp := range histogram.DataPoints {
+		count += dp.Count
+	}
+
+	return count
+}
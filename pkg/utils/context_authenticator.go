@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/codefresh-io/go-sdk/pkg/client"
+)
+
+type (
+	contextAuthenticator struct {
+		path  string
+		name  string
+		mu    sync.Mutex
+		token string
+	}
+)
+
+// NewContextAuthenticator returns a client.Authenticator that uses the token of a .cfconfig context
+// (or the current context, if name is empty). The file is read again when the token is rejected.
+func NewContextAuthenticator(path string, name string) client.Authenticator {
+	return &contextAuthenticator{path: path, name: name}
+}
+
+func (a *contextAuthenticator) Token(_ context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" {
+		return a.token, nil
+	}
+
+	return a.read()
+}
+
+func (a *contextAuthenticator) Refresh(_ context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	_, err := a.read()
+	return err
+}
+
+func (a *contextAuthenticator) read() (string, error) {
+	cfContext, err := ReadAuthContext(a.path, a.name)
+	if err != nil {
+		return "", fmt.Errorf("failed reading auth context: %w", err)
+	}
+
+	if cfContext == nil || cfContext.Token == "" {
+		return "", fmt.Errorf("auth context %q has no token", a.name)
+	}
+
+	a.token = cfContext.Token
+	return a.token, nil
+}
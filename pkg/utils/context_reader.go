@@ -1,8 +1,12 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
 
 	"sigs.k8s.io/yaml"
 )
@@ -29,35 +33,230 @@ type (
 	}
 )
 
+const (
+	// CFConfigEnvVar overrides the default config file path
+	CFConfigEnvVar = "CFCONFIG"
+
+	defaultCFConfigName = ".cfconfig"
+)
+
+var (
+	ErrConfigNotFound  = errors.New("codefresh config file not found")
+	ErrContextNotFound = errors.New("context not found")
+	ErrContextExists   = errors.New("context already exists")
+)
+
+// DefaultCFConfigPath returns the value of $CFCONFIG, or ~/.cfconfig
+func DefaultCFConfigPath() (string, error) {
+	if path := os.Getenv(CFConfigEnvVar); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed getting home directory: %w", err)
+	}
+
+	return filepath.Join(home, defaultCFConfigName), nil
+}
+
+// ReadAuthContext returns the named context from the config file, or the current context if name is empty.
+// An empty path means the default config path.
 func ReadAuthContext(path string, name string) (*CFContext, error) {
-	config, err := getCFConfig(path)
+	config, err := LoadCFConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.GetContext(name)
+}
+
+// LoadCFConfig reads the config file. An empty path means the default config path.
+func LoadCFConfig(path string) (*CFConfig, error) {
+	path, err := resolveCFConfigPath(path)
 	if err != nil {
 		return nil, err
 	}
 
-	var context *CFContext
-	if name != "" {
-		context = config.Contexts[name]
-	} else {
-		context = config.Contexts[config.CurrentContext]
+	return getCFConfig(path)
+}
+
+// UpdateCFConfig locks the config file, applies update to its content and saves it.
+// A missing file is treated as an empty config.
+func UpdateCFConfig(path string, update func(config *CFConfig) error) error {
+	path, err := resolveCFConfigPath(path)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, err := getCFConfig(path)
+	if errors.Is(err, ErrConfigNotFound) {
+		config, err = &CFConfig{}, nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = update(config)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, config)
+}
+
+// Save writes the config to path atomically. An empty path means the default config path.
+func (c *CFConfig) Save(path string) error {
+	path, err := resolveCFConfigPath(path)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return writeFileAtomic(path, c)
+}
+
+// GetContext returns the named context, or the current context if name is empty
+func (c *CFConfig) GetContext(name string) (*CFContext, error) {
+	if name == "" {
+		name = c.CurrentContext
+		if name == "" {
+			return nil, fmt.Errorf("no current context is set: %w", ErrContextNotFound)
+		}
+	}
+
+	context, ok := c.Contexts[name]
+	if !ok || context == nil {
+		return nil, fmt.Errorf("%w: %q", ErrContextNotFound, name)
 	}
 
 	return context, nil
 }
 
+// ListContexts returns all contexts, sorted by name
+func (c *CFConfig) ListContexts() []*CFContext {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	contexts := make([]*CFContext, 0, len(names))
+	for _, name := range names {
+		contexts = append(contexts, c.Contexts[name])
+	}
+
+	return contexts
+}
+
+// AddContext adds a new context, using its Name as the key
+func (c *CFConfig) AddContext(context *CFContext) error {
+	if context == nil || context.Name == "" {
+		return errors.New("context name is required")
+	}
+
+	if _, ok := c.Contexts[context.Name]; ok {
+		return fmt.Errorf("%w: %q", ErrContextExists, context.Name)
+	}
+
+	if c.Contexts == nil {
+		c.Contexts = map[string]*CFContext{}
+	}
+
+	c.Contexts[context.Name] = context
+	return nil
+}
+
+// RemoveContext removes a context, and unsets it if it was the current context
+func (c *CFConfig) RemoveContext(name string) error {
+	if _, ok := c.Contexts[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrContextNotFound, name)
+	}
+
+	delete(c.Contexts, name)
+	if c.CurrentContext == name {
+		c.CurrentContext = ""
+	}
+
+	return nil
+}
+
+// RenameContext renames a context, keeping it as the current context if it was one
+func (c *CFConfig) RenameContext(oldName, newName string) error {
+	context, ok := c.Contexts[oldName]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrContextNotFound, oldName)
+	}
+
+	if newName == "" {
+		return errors.New("context name is required")
+	}
+
+	if oldName == newName {
+		return nil
+	}
+
+	if _, ok := c.Contexts[newName]; ok {
+		return fmt.Errorf("%w: %q", ErrContextExists, newName)
+	}
+
+	delete(c.Contexts, oldName)
+	if context != nil {
+		context.Name = newName
+	}
+
+	c.Contexts[newName] = context
+	if c.CurrentContext == oldName {
+		c.CurrentContext = newName
+	}
+
+	return nil
+}
+
+// SetCurrentContext sets the current context to an existing context
+func (c *CFConfig) SetCurrentContext(name string) error {
+	if _, ok := c.Contexts[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrContextNotFound, name)
+	}
+
+	c.CurrentContext = name
+	return nil
+}
+
+func resolveCFConfigPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+
+	return DefaultCFConfigPath()
+}
+
 func getCFConfig(path string) (*CFConfig, error) {
 	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %w", ErrConfigNotFound, err)
+	}
+
 	if err != nil {
-		fmt.Printf("Error reading file\n")
-		return nil, err
+		return nil, fmt.Errorf("failed reading config file: %w", err)
 	}
 
 	config := CFConfig{}
 	err = yaml.Unmarshal(content, &config)
 	if err != nil {
-		fmt.Printf("Error unmarshaling content\n")
-		fmt.Println(err.Error())
-		return nil, err
+		return nil, fmt.Errorf("failed unmarshaling config file %s: %w", path, err)
 	}
 
 	return &config, nil
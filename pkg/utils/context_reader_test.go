@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCFConfig = `contexts:
+  first:
+    name: first
+    type: APIKey
+    url: https://g.codefresh.io
+    token: first-token
+  second:
+    name: second
+    type: APIKey
+    url: https://onprem.example.com
+    token: second-token
+    onPrem: true
+current-context: first
+`
+
+func writeTestConfig(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), ".cfconfig")
+	if err := os.WriteFile(path, []byte(testCFConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestReadAuthContext(t *testing.T) {
+	tests := []struct {
+		name      string
+		context   string
+		wantToken string
+		wantErr   error
+	}{
+		{
+			name:      "should return current context when name is empty",
+			wantToken: "first-token",
+		},
+		{
+			name:      "should return named context",
+			context:   "second",
+			wantToken: "second-token",
+		},
+		{
+			name:    "should fail when context does not exist",
+			context: "third",
+			wantErr: ErrContextNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestConfig(t)
+			got, err := ReadAuthContext(path, tt.context)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantToken, got.Token)
+		})
+	}
+}
+
+func TestLoadCFConfig(t *testing.T) {
+	t.Run("should fail with ErrConfigNotFound when file is missing", func(t *testing.T) {
+		_, err := LoadCFConfig(filepath.Join(t.TempDir(), "missing"))
+		assert.ErrorIs(t, err, ErrConfigNotFound)
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("should honor CFCONFIG env var", func(t *testing.T) {
+		path := writeTestConfig(t)
+		t.Setenv(CFConfigEnvVar, path)
+		config, err := LoadCFConfig("")
+		assert.NoError(t, err)
+		assert.Equal(t, "first", config.CurrentContext)
+	})
+}
+
+func TestCFConfig_Contexts(t *testing.T) {
+	config, err := LoadCFConfig(writeTestConfig(t))
+	assert.NoError(t, err)
+
+	contexts := config.ListContexts()
+	assert.Len(t, contexts, 2)
+	assert.Equal(t, "first", contexts[0].Name)
+	assert.Equal(t, "second", contexts[1].Name)
+
+	assert.ErrorIs(t, config.AddContext(&CFContext{Name: "first"}), ErrContextExists)
+	assert.NoError(t, config.AddContext(&CFContext{Name: "third", Token: "third-token"}))
+
+	assert.ErrorIs(t, config.SetCurrentContext("missing"), ErrContextNotFound)
+	assert.NoError(t, config.SetCurrentContext("third"))
+
+	assert.ErrorIs(t, config.RenameContext("third", "second"), ErrContextExists)
+	assert.NoError(t, config.RenameContext("third", "renamed"))
+	assert.Equal(t, "renamed", config.CurrentContext)
+	current, err := config.GetContext("")
+	assert.NoError(t, err)
+	assert.Equal(t, "renamed", current.Name)
+	assert.Equal(t, "third-token", current.Token)
+
+	assert.NoError(t, config.RemoveContext("renamed"))
+	assert.Equal(t, "", config.CurrentContext)
+	assert.ErrorIs(t, config.RemoveContext("renamed"), ErrContextNotFound)
+	_, err = config.GetContext("")
+	assert.ErrorIs(t, err, ErrContextNotFound)
+}
+
+func TestCFConfig_Save(t *testing.T) {
+	path := writeTestConfig(t)
+	config, err := LoadCFConfig(path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, config.SetCurrentContext("second"))
+	assert.NoError(t, config.Save(path))
+
+	reloaded, err := LoadCFConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, config, reloaded)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0600), info.Mode().Perm())
+	_, err = os.Stat(path + ".lock")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestUpdateCFConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".cfconfig")
+	err := UpdateCFConfig(path, func(config *CFConfig) error {
+		if err := config.AddContext(&CFContext{Name: "new", Token: "new-token"}); err != nil {
+			return err
+		}
+
+		return config.SetCurrentContext("new")
+	})
+	assert.NoError(t, err)
+
+	context, err := ReadAuthContext(path, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "new-token", context.Token)
+
+	err = UpdateCFConfig(path, func(config *CFConfig) error {
+		return config.RemoveContext("missing")
+	})
+	assert.ErrorIs(t, err, ErrContextNotFound)
+}
+
+func Test_lockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".cfconfig")
+	unlock, err := lockFile(path)
+	assert.NoError(t, err)
+
+	_, err = os.Stat(path + ".lock")
+	assert.NoError(t, err)
+
+	unlock()
+	if runtime.GOOS != "windows" {
+		_, err = os.Stat(path + ".lock")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	}
+}
+
+func Test_lockFile_WaitsForHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".cfconfig")
+	unlock, err := lockFile(path)
+	assert.NoError(t, err)
+
+	locked := make(chan struct{})
+	go func() {
+		unlockSecond, err := lockFile(path)
+		assert.NoError(t, err)
+		close(locked)
+		unlockSecond()
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("lock was taken while it was held")
+	case <-time.After(3 * lockRetryInterval):
+	}
+
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("lock was not taken after it was released")
+	}
+}
+
+func Test_lockFile_LeftoverLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".cfconfig")
+	// a crashed process leaves the lock file behind, without holding the lock
+	err := os.WriteFile(path+".lock", nil, 0600)
+	assert.NoError(t, err)
+
+	unlock, err := lockFile(path)
+	assert.NoError(t, err)
+	unlock()
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	lockRetryInterval = 50 * time.Millisecond
+	lockTimeout       = 10 * time.Second
+)
+
+// lockFile takes an exclusive OS advisory lock on "<path>.lock", waiting for other holders to release it.
+// The OS releases the lock of a crashed process, so there are no stale locks to clean up.
+// The returned function releases the lock.
+func lockFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		unlock, err := tryLockFile(lockPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed locking config file: %w", err)
+		}
+
+		if unlock != nil {
+			return unlock, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed locking config file: timed out waiting for %s", lockPath)
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// writeFileAtomic marshals v to yaml and replaces the file at path, so readers never see a partial file
+func writeFileAtomic(path string, v any) error {
+	content, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed marshaling config file: %w", err)
+	}
+
+	mode := fs.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed writing config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(content)
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed writing config file: %w", err)
+	}
+
+	return nil
+}
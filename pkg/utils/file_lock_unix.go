@@ -0,0 +1,47 @@
+//go:build !windows
+
+package utils
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockFile returns nil without an error when another process holds the lock.
+// The lock file is removed on release, so a lock taken on a file that was removed meanwhile is retried.
+func tryLockFile(lockPath string) (func(), error) {
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err != nil {
+		_ = f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	locked, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	current, err := os.Stat(lockPath)
+	if err != nil || !os.SameFile(locked, current) {
+		// the previous holder removed the file after it was opened here
+		_ = f.Close()
+		return nil, nil
+	}
+
+	return func() {
+		// removing before closing keeps waiting processes from locking the removed file
+		_ = os.Remove(lockPath)
+		_ = f.Close()
+	}, nil
+}
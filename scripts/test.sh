@@ -9,7 +9,14 @@ mkdir cover/
 echo "running go test"
 go test -v -race -coverprofile=cover/cover.out -covermode=atomic ./...
 code=$?
-echo "go test cmd exited with code $code"
+
+echo "running go test in the telemetry module"
+telemetryCode=0
+(cd pkg/telemetry && go test -v -race -coverprofile=../../cover/cover-telemetry.out -covermode=atomic ./...) || telemetryCode=$?
+echo "go test cmd exited with code $code, $telemetryCode in the telemetry module"
+if [ $code -eq 0 ]; then
+    code=$telemetryCode
+fi
 
 echo "running go tool cover"
 go tool cover -html=cover/cover.out -o=cover/coverage.html